@@ -4,16 +4,23 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // Account represents the user's account. Used when retrieving e.g current
 // balance.
 type Account struct {
 	client *Client
+
+	// SecretMaxAge is how long an API secret may be used for before
+	// SecretsExpiringSoon reports it. Defaults to DefaultSecretMaxAge.
+	SecretMaxAge time.Duration
 }
 
 // GetBalance retrieves the current balance of your Nexmo account in Euros (€)
-func (nexmo *Account) GetBalance() (float64, error) {
+func (nexmo *Account) GetBalance() (balance float64, err error) {
 	// Declare this locally, since we are only going to return a float64.
 	type AccountBalance struct {
 		Value float64 `json:"value"`
@@ -21,17 +28,15 @@ func (nexmo *Account) GetBalance() (float64, error) {
 
 	var accBalance *AccountBalance
 
-	client := &http.Client{}
-	r, _ := http.NewRequest("GET", apiRoot+"/account/get-balance/"+
+	r, _ := http.NewRequest("GET", nexmo.client.restURL+"/account/get-balance/"+
 		nexmo.client.apiKey+"/"+nexmo.client.apiSecret, nil)
-	r.Header.Add("Accept", "application/json")
-
-	resp, err := client.Do(r)
-	defer resp.Body.Close()
 
+	defer nexmo.client.wrapError(r, &err)
+	resp, err := nexmo.client.do(r)
 	if err != nil {
 		return 0.0, err
 	}
+	defer resp.Body.Close()
 
 	body, _ := ioutil.ReadAll(resp.Body)
 
@@ -41,3 +46,43 @@ func (nexmo *Account) GetBalance() (float64, error) {
 	}
 	return accBalance.Value, nil
 }
+
+// AccountSettings holds the account-wide callback URLs and request limits.
+type AccountSettings struct {
+	MOCallbackURL      string `json:"mo-callback-url"`
+	DRCallbackURL      string `json:"dr-callback-url"`
+	MaxOutboundRequest int    `json:"max-outbound-request"`
+	MaxInboundRequest  int    `json:"max-inbound-request"`
+	MaxCallsPerSecond  int    `json:"max-calls-per-second"`
+}
+
+/*
+	POST /account/settings?api_key={api_key}&api_secret={api_secret}
+*/
+
+// GetSettings retrieves the account's current settings, including the
+// inbound message and delivery receipt callback URLs.
+func (nexmo *Account) GetSettings() (settings *AccountSettings, err error) {
+	values := url.Values{}
+	values.Set("api_key", nexmo.client.apiKey)
+	values.Set("api_secret", nexmo.client.apiSecret)
+
+	r, _ := http.NewRequest("POST", nexmo.client.restURL+"/account/settings",
+		strings.NewReader(values.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	defer nexmo.client.wrapError(r, &err)
+	resp, err := nexmo.client.do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	err = json.Unmarshal(body, &settings)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
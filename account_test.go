@@ -1,7 +1,10 @@
 package nexmo
 
 import (
+	"context"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestGetAccountBalance(t *testing.T) {
@@ -17,3 +20,135 @@ func TestGetAccountBalance(t *testing.T) {
 
 	t.Log("Got account balance: ", balance, "€")
 }
+
+func TestGetAccountSettings(t *testing.T) {
+	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	if err != nil {
+		t.Error("Failed to create Client with error:", err)
+	}
+
+	settings, err := nexmo.Account.GetSettings()
+	if err != nil {
+		t.Error("Failed to get account settings with error:", err)
+	}
+
+	t.Logf("Got account settings: %+v", settings)
+}
+
+func TestGetPricing(t *testing.T) {
+	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	if err != nil {
+		t.Error("Failed to create Client with error:", err)
+	}
+
+	pricing, err := nexmo.Account.GetPricing("GB")
+	if err != nil {
+		t.Error("Failed to get pricing with error:", err)
+	} else if len(pricing.Networks) == 0 {
+		t.Error("Pricing should have at least one network")
+	}
+
+	t.Logf("Got pricing: %+v", pricing)
+}
+
+func TestGetCurrency(t *testing.T) {
+	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	if err != nil {
+		t.Error("Failed to create Client with error:", err)
+	}
+
+	currency, err := nexmo.Account.Currency()
+	if err != nil {
+		t.Error("Failed to get account currency with error:", err)
+	}
+
+	t.Log("Got account currency:", currency)
+}
+
+func TestNetworkName(t *testing.T) {
+	var fullFetches int
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/account/get-full-pricing/outbound/sms" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		fullFetches++
+		w.Write([]byte(`{"countries": [{"countryCode": "US", "currency": "EUR", "defaultPrice": "0.0062", "networks": [
+			{"networkCode": "310260", "networkName": "T-Mobile US", "price": "0.0062"},
+			{"networkCode": "310410", "networkName": "AT&T Mobility", "price": "0.0062"}]}]}`))
+	}))
+
+	name, err := nexmo.Account.NetworkName("310260")
+	if err != nil || name != "T-Mobile US" {
+		t.Fatalf("NetworkName = %q, %v, want T-Mobile US", name, err)
+	}
+	if name, _ := nexmo.Account.NetworkName("310410"); name != "AT&T Mobility" {
+		t.Errorf("NetworkName = %q, want AT&T Mobility", name)
+	}
+	if _, err := nexmo.Account.NetworkName("99999"); err != ErrUnknownNetwork {
+		t.Errorf("NetworkName of an unknown code = %v, want ErrUnknownNetwork", err)
+	}
+	if fullFetches != 1 {
+		t.Errorf("Fetched the full pricing %d times, want once", fullFetches)
+	}
+
+	clk.Advance(DefaultReferenceDataTTL + time.Second)
+	if _, err := nexmo.Account.NetworkName("99999"); err != ErrUnknownNetwork || fullFetches != 2 {
+		t.Errorf("Expected an unknown code to refresh expired pricing, got %v after %d fetches", err, fullFetches)
+	}
+}
+
+func TestRefreshReferenceData(t *testing.T) {
+	var fetches int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/account/get-full-pricing/outbound/sms":
+			fetches++
+			w.Write([]byte(`{"countries": [
+				{"countryCode": "GB", "currency": "EUR", "defaultPrice": "0.0333", "networks": [
+					{"networkCode": "23410", "networkName": "O2 UK", "price": "0.0333"}]},
+				{"countryCode": "US", "currency": "EUR", "defaultPrice": "0.0062"}]}`))
+		case "/sms/json":
+			w.Write([]byte(`{"message-count": "2", "messages": [
+				{"status": "0", "message-id": "0A00000001", "message-price": "0.0333", "remaining-balance": "10.0333"},
+				{"status": "0", "message-id": "0A00000002", "message-price": "0.0333", "remaining-balance": "10.0000"}]}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	resp, err := nexmo.SMS.Send(msg)
+	if err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	if cost := resp.Cost(); cost.Currency != "" {
+		t.Errorf("Cost before the currency was cached = %v, want no currency", cost)
+	}
+
+	if err := nexmo.RefreshReferenceData(context.Background()); err != nil {
+		t.Fatal("RefreshReferenceData failed with error:", err)
+	}
+	if pricing, err := nexmo.Account.GetPricing("GB"); err != nil || pricing.DefaultPrice != 0.0333 {
+		t.Errorf("GetPricing = %+v, %v, want the refreshed pricing", pricing, err)
+	}
+	if name, err := nexmo.Account.NetworkName("23410"); err != nil || name != "O2 UK" {
+		t.Errorf("NetworkName = %q, %v, want O2 UK", name, err)
+	}
+	if currency, err := nexmo.Account.Currency(); err != nil || currency != "EUR" {
+		t.Errorf("Currency = %q, %v, want EUR", currency, err)
+	}
+	if fetches != 1 {
+		t.Errorf("Fetched the full pricing %d times, want once", fetches)
+	}
+
+	msg = &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if resp, err = nexmo.SMS.Send(msg); err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	if cost := resp.Cost(); cost != (Money{0.0666, "EUR"}) {
+		t.Errorf("Cost = %v, want 0.0666 EUR", cost)
+	}
+	if balance, ok := resp.Balance(); !ok || balance != (Money{10, "EUR"}) {
+		t.Errorf("Balance = %v, %t, want 10 EUR", balance, ok)
+	}
+}
@@ -0,0 +1,80 @@
+package nexmo
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffStrategy decides how long to wait before retrying a failed request.
+// NextDelay is called with the number of attempts made so far, starting at 1,
+// and the error of the last one. It returns false to give up.
+type BackoffStrategy interface {
+	NextDelay(attempt int, lastErr error) (time.Duration, bool)
+}
+
+// ExponentialBackoff waits Base, then twice as long after each further
+// failure, up to Max if it is set. It gives up after MaxAttempts attempts.
+type ExponentialBackoff struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+func (b ExponentialBackoff) NextDelay(attempt int, lastErr error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	delay := float64(b.Base) * math.Pow(2, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		return b.Max, true
+	}
+	return capDuration(delay), true
+}
+
+// ConstantBackoff waits Delay between attempts. It gives up after
+// MaxAttempts attempts.
+type ConstantBackoff struct {
+	Delay       time.Duration
+	MaxAttempts int
+}
+
+func (b ConstantBackoff) NextDelay(attempt int, lastErr error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	return b.Delay, true
+}
+
+// DecorrelatedJitterBackoff waits a random time between Base and three times
+// the previous ceiling, capped at Max, so that many clients retrying at once
+// spread out. It gives up after MaxAttempts attempts.
+type DecorrelatedJitterBackoff struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+func (b DecorrelatedJitterBackoff) NextDelay(attempt int, lastErr error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	ceiling := float64(b.Base) * math.Pow(3, float64(attempt))
+	if b.Max > 0 && ceiling > float64(b.Max) {
+		ceiling = float64(b.Max)
+	}
+	if ceiling <= float64(b.Base) {
+		return b.Base, true
+	}
+	return b.Base + time.Duration(rand.Int63n(int64(capDuration(ceiling)-b.Base))), true
+}
+
+// maxBackoff bounds computed delays so that they can't overflow.
+const maxBackoff = time.Duration(math.MaxInt64 / 2)
+
+func capDuration(d float64) time.Duration {
+	if d > float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(d)
+}
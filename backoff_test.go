@@ -0,0 +1,137 @@
+package nexmo
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var backoffTests = []struct {
+	name      string
+	backoff   BackoffStrategy
+	attempt   int
+	want      time.Duration
+	wantRetry bool
+}{
+	{"exponential first", ExponentialBackoff{Base: time.Second, MaxAttempts: 5}, 1, time.Second, true},
+	{"exponential third", ExponentialBackoff{Base: time.Second, MaxAttempts: 5}, 3, 4 * time.Second, true},
+	{"exponential capped", ExponentialBackoff{Base: time.Second, Max: 3 * time.Second, MaxAttempts: 5}, 3, 3 * time.Second, true},
+	{"exponential uncapped", ExponentialBackoff{Base: time.Second, MaxAttempts: 1000}, 999, maxBackoff, true},
+	{"exponential give up", ExponentialBackoff{Base: time.Second, MaxAttempts: 5}, 5, 0, false},
+	{"constant", ConstantBackoff{Delay: time.Second, MaxAttempts: 3}, 2, time.Second, true},
+	{"constant give up", ConstantBackoff{Delay: time.Second, MaxAttempts: 3}, 3, 0, false},
+	{"jitter give up", DecorrelatedJitterBackoff{Base: time.Second, MaxAttempts: 2}, 2, 0, false},
+}
+
+func TestBackoffStrategies(t *testing.T) {
+	for _, test := range backoffTests {
+		got, retry := test.backoff.NextDelay(test.attempt, ErrThrottled)
+		if got != test.want || retry != test.wantRetry {
+			t.Errorf("%s: NextDelay(%d) = %v, %v, want %v, %v",
+				test.name, test.attempt, got, retry, test.want, test.wantRetry)
+		}
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	b := DecorrelatedJitterBackoff{Base: time.Second, Max: 10 * time.Second, MaxAttempts: 100}
+	for attempt := 1; attempt < 100; attempt++ {
+		got, retry := b.NextDelay(attempt, ErrThrottled)
+		if !retry || got < b.Base || got > b.Max {
+			t.Fatalf("NextDelay(%d) = %v, %v, want between %v and %v", attempt, got, retry, b.Base, b.Max)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	var calls int32
+	var bodies []string
+	var statuses []int
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if n := int(atomic.AddInt32(&calls, 1)); n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+		}
+	}))
+
+	var errs []error
+	nexmo.Backoff = backoffFunc(func(attempt int, lastErr error) (time.Duration, bool) {
+		errs = append(errs, lastErr)
+		return time.Minute, true
+	})
+
+	statuses = []int{http.StatusTooManyRequests, http.StatusBadGateway}
+	start := clk.Now()
+	r, _ := http.NewRequest("GET", nexmo.restURL, nil)
+	resp, err := nexmo.do(r)
+	if err != nil {
+		t.Fatal("Request failed with error:", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("Got status %d after %d calls, want 200 after 3", resp.StatusCode, calls)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrThrottled) {
+		t.Errorf("Backoff was called with %v, want ErrThrottled then a 502 error", errs)
+	}
+	if waited := clk.Now().Sub(start); waited != 2*time.Minute {
+		t.Errorf("Waited %v between attempts, want 2m", waited)
+	}
+
+	// A throttled POST was not carried out, so is retried with its body.
+	calls, bodies = 0, nil
+	statuses = []int{http.StatusTooManyRequests}
+	r, _ = http.NewRequest("POST", nexmo.restURL, strings.NewReader("text=hello"))
+	resp, err = nexmo.do(r)
+	if err != nil {
+		t.Fatal("Request failed with error:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("Got status %d after %d calls, want 200 after 2", resp.StatusCode, calls)
+	}
+	for i, body := range bodies {
+		if body != "text=hello" {
+			t.Errorf("Attempt %d sent body %q", i+1, body)
+		}
+	}
+
+	// A POST failing with a 5xx may have been carried out, so isn't.
+	calls = 0
+	statuses = []int{http.StatusBadGateway}
+	r, _ = http.NewRequest("POST", nexmo.restURL, strings.NewReader("text=hello"))
+	resp, err = nexmo.do(r)
+	if err != nil {
+		t.Fatal("Request failed with error:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls != 1 {
+		t.Errorf("Got status %d after %d calls, want 502 after 1", resp.StatusCode, calls)
+	}
+
+	// Without a Backoff, failures are returned as they are.
+	nexmo.Backoff = nil
+	calls = 0
+	statuses = []int{http.StatusTooManyRequests}
+	r, _ = http.NewRequest("GET", nexmo.restURL, nil)
+	resp, err = nexmo.do(r)
+	if err != nil {
+		t.Fatal("Request failed with error:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls != 1 {
+		t.Errorf("Got status %d after %d calls, want 429 after 1", resp.StatusCode, calls)
+	}
+}
+
+type backoffFunc func(attempt int, lastErr error) (time.Duration, bool)
+
+func (f backoffFunc) NextDelay(attempt int, lastErr error) (time.Duration, bool) {
+	return f(attempt, lastErr)
+}
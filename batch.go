@@ -0,0 +1,225 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrInsufficientBalance is the error of every message in a batch that wasn't
+// sent because the account balance was below SMS.MinBalance.
+var ErrInsufficientBalance = errors.New("Account balance is below the minimum for sending")
+
+// ErrDuplicateRecipient is the error of every message in a batch that wasn't
+// sent because an earlier message went to the same number, when
+// SMS.SkipDuplicateRecipients is set.
+var ErrDuplicateRecipient = errors.New("Skipped duplicate recipient")
+
+// SendResult is the outcome of sending a message to a single recipient.
+type SendResult struct {
+	To string
+
+	// MessageID is the ID of the first part of the message.
+	MessageID string
+
+	// Status is the first unsuccessful status of any part of the message,
+	// or ResponseSuccess. It is only meaningful when the message reached
+	// Nexmo; check Err first.
+	Status ResponseCode
+
+	// Price is the total price of all parts of the message.
+	Price float64
+
+	// CorrelationID is the MessageResponse.CorrelationID of the send, if it
+	// got that far.
+	CorrelationID string
+
+	// RemainingBalance is the account balance Nexmo reported after sending
+	// the message. See MessageResponse.RemainingBalance.
+	RemainingBalance float64
+	hasBalance       bool
+
+	// Err is set if the message was not sent, whether because of a
+	// transport error, a validation error or a status other than
+	// ResponseSuccess.
+	Err error
+}
+
+// newSendResult combines the response to a message to to, and the error
+// sending it, into a SendResult.
+func newSendResult(to string, resp *MessageResponse, err error) SendResult {
+	result := SendResult{To: to, Err: err}
+	if resp == nil {
+		return result
+	}
+	result.CorrelationID = resp.CorrelationID
+	result.RemainingBalance, result.hasBalance = resp.RemainingBalance()
+	for i, report := range resp.Messages {
+		if i == 0 {
+			result.MessageID = report.MessageID
+		}
+		if price, err := strconv.ParseFloat(report.MessagePrice, 64); err == nil {
+			result.Price += price
+		}
+		if report.Status != ResponseSuccess && result.Status == ResponseSuccess {
+			result.Status = report.Status
+			if result.Err == nil {
+				result.Err = fmt.Errorf("Message to %s failed: %s (%s)",
+					to, report.Status, report.ErrorText)
+			}
+		}
+	}
+	return result
+}
+
+// SendBatch sends every message in msgs as SendBatchStream does, and returns
+// their results in the same order as msgs.
+func (c *SMS) SendBatch(msgs []*SMSMessage) []SendResult {
+	results := make([]SendResult, len(msgs))
+	c.SendBatchStream(msgs, func(index int, resp *MessageResponse, err error) {
+		results[index] = newSendResult(msgs[index].To, resp, err)
+	})
+	return results
+}
+
+// BatchRemainingBalance returns the lowest remaining balance reported for any
+// message in results, which is the balance after the batch as of the last
+// send, and false if none was reported. Messages may be sent concurrently, so
+// the lowest balance is used rather than that of the last result. Like
+// MessageResponse.RemainingBalance, it reflects the balance at the time of
+// sending, not a live query.
+func BatchRemainingBalance(results []SendResult) (float64, bool) {
+	balance, ok := 0.0, false
+	for _, result := range results {
+		if result.hasBalance && (!ok || result.RemainingBalance < balance) {
+			balance, ok = result.RemainingBalance, true
+		}
+	}
+	return balance, ok
+}
+
+// SendBatchStream sends every message in msgs and calls fn with each result as
+// soon as it is available, along with the message's index in msgs. Up to
+// BatchConcurrency messages are in flight at once, and the rate limit applies
+// as it does to Send. Calls to fn are never concurrent. SendBatchStream
+// returns once every message has been handled. If MinBalance is set and the
+// balance is below it, or can't be found, no message is sent and fn gets the
+// same error for each. Duplicates skipped because of SkipDuplicateRecipients
+// get ErrDuplicateRecipient.
+func (c *SMS) SendBatchStream(msgs []*SMSMessage, fn func(index int, resp *MessageResponse, err error)) {
+	c.SendBatchStreamContext(context.Background(), msgs, fn)
+}
+
+// SendBatchStreamContext is like SendBatchStream, but sends with ctx. Once
+// ctx is done no more messages are started, and fn gets ctx.Err() for each
+// of the messages that weren't.
+func (c *SMS) SendBatchStreamContext(ctx context.Context, msgs []*SMSMessage, fn func(index int, resp *MessageResponse, err error)) {
+	if err := c.checkBalance(); err != nil {
+		for i := range msgs {
+			fn(i, nil, err)
+		}
+		return
+	}
+
+	duplicates := c.duplicateRecipients(msgs)
+
+	var fnMutex sync.Mutex
+	runBatch(len(msgs), c.BatchConcurrency, func(i int) {
+		var resp *MessageResponse
+		var err error
+		switch {
+		case duplicates[i]:
+			err = ErrDuplicateRecipient
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		default:
+			resp, err = c.SendContext(ctx, msgs[i])
+		}
+		fnMutex.Lock()
+		fn(i, resp, err)
+		fnMutex.Unlock()
+	})
+}
+
+// runBatch calls fn with every index from 0 to n-1, from up to workers
+// goroutines at once, and returns once every call has returned. A workers
+// below 1 means 1.
+func runBatch(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// batchError summarises the failures in a batch of n items, described by
+// what, e.g. "number updates", for which failed reports true. It returns
+// nil if none failed.
+func batchError(n int, what string, failed func(i int) bool) error {
+	count := 0
+	for i := 0; i < n; i++ {
+		if failed(i) {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d %s failed", count, n, what)
+}
+
+// checkBalance returns ErrInsufficientBalance if MinBalance is set and the
+// account balance is below it.
+func (c *SMS) checkBalance() error {
+	if c.MinBalance <= 0 {
+		return nil
+	}
+	balance, err := c.client.Account.GetBalance()
+	if err != nil {
+		return fmt.Errorf("Could not check the account balance: %w", err)
+	}
+	if balance < c.MinBalance {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+// duplicateRecipients returns the indexes of the messages in msgs to skip
+// because an earlier one goes to the same number, if SkipDuplicateRecipients
+// is set.
+func (c *SMS) duplicateRecipients(msgs []*SMSMessage) map[int]bool {
+	if !c.SkipDuplicateRecipients {
+		return nil
+	}
+	duplicates := map[int]bool{}
+	seen := make(map[string]bool, len(msgs))
+	for i, msg := range msgs {
+		to := normalizeMSISDN(msg.To)
+		if to == "" {
+			continue
+		}
+		if seen[to] {
+			duplicates[i] = true
+		}
+		seen[to] = true
+	}
+	return duplicates
+}
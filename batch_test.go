@@ -0,0 +1,243 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendBatch(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.PostForm.Get("to") {
+		case "447700900001":
+			w.Write([]byte(`{"message-count": "2", "messages": [
+				{"status": "0", "message-id": "0A00000001", "to": "447700900001", "message-price": "0.03330000"},
+				{"status": "0", "message-id": "0A00000002", "to": "447700900001", "message-price": "0.03330000"}]}`))
+		case "447700900002":
+			w.Write([]byte(`{"message-count": "1", "messages": [
+				{"status": "7", "to": "447700900002", "error-text": "Number barred"}]}`))
+		}
+	}))
+	nexmo.SMS.BatchConcurrency = 2
+
+	msgs := []*SMSMessage{
+		{From: "gonexmo", To: "447700900001", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "447700900002", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "", Type: Text, Text: "Hello"},
+	}
+	results := nexmo.SMS.SendBatch(msgs)
+	if len(results) != 3 {
+		t.Fatalf("Got %d results, want 3", len(results))
+	}
+
+	if r := results[0]; r.Err != nil || r.To != "447700900001" || r.MessageID != "0A00000001" ||
+		r.Status != ResponseSuccess || r.Price != 0.0666 {
+		t.Errorf("Unexpected result for a sent message: %+v", r)
+	}
+	if r := results[1]; r.Err == nil || r.Status != ResponseNumberBarred {
+		t.Errorf("Unexpected result for a rejected message: %+v", r)
+	}
+	if r := results[2]; r.Err == nil || r.MessageID != "" {
+		t.Errorf("Unexpected result for an invalid message: %+v", r)
+	}
+}
+
+func TestSendBatchMinBalance(t *testing.T) {
+	var sent int
+	balance := "0.5"
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/account/get-balance/") {
+			w.Write([]byte(`{"value": ` + balance + `, "autoReload": false}`))
+			return
+		}
+		sent++
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.MinBalance = 1
+
+	msgs := []*SMSMessage{
+		{From: "gonexmo", To: "447700900001", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "447700900002", Type: Text, Text: "Hello"},
+	}
+	for _, r := range nexmo.SMS.SendBatch(msgs) {
+		if r.Err != ErrInsufficientBalance {
+			t.Errorf("Result with a low balance = %+v, want ErrInsufficientBalance", r)
+		}
+	}
+	if sent != 0 {
+		t.Errorf("Sent %d messages with a low balance, want none", sent)
+	}
+
+	if _, err := nexmo.SMS.Send(msgs[0]); err != nil || sent != 1 {
+		t.Errorf("Send with a low balance = %v, want it sent without a balance check", err)
+	}
+
+	balance = "10.0"
+	sent = 0
+	for _, r := range nexmo.SMS.SendBatch(msgs) {
+		if r.Err != nil {
+			t.Errorf("Unexpected error with enough balance: %v", r.Err)
+		}
+	}
+	if sent != 2 {
+		t.Errorf("Sent %d messages with enough balance, want 2", sent)
+	}
+
+	// A failed balance check keeps the cause.
+	balance = "not a number"
+	for _, r := range nexmo.SMS.SendBatch(msgs) {
+		var requestErr *RequestError
+		if !errors.As(r.Err, &requestErr) {
+			t.Errorf("Result when the balance check failed = %v, want it to wrap a *RequestError", r.Err)
+		}
+	}
+}
+
+func TestSendBatchSkipDuplicateRecipients(t *testing.T) {
+	sent := map[string]int{}
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sent[r.PostForm.Get("to")]++
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.SkipDuplicateRecipients = true
+
+	msgs := []*SMSMessage{
+		{From: "gonexmo", To: "447700900001", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "447700900002", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "+44 7700 900001", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "00447700900002", Type: Text, Text: "Hello"},
+	}
+	results := nexmo.SMS.SendBatch(msgs)
+	for i, r := range results {
+		if wantSkipped := i >= 2; (r.Err == ErrDuplicateRecipient) != wantSkipped {
+			t.Errorf("Result %d = %+v, want skipped: %v", i, r, wantSkipped)
+		}
+	}
+	if len(sent) != 2 || sent["447700900001"] != 1 || sent["447700900002"] != 1 {
+		t.Errorf("Sent %v, want one message to each number", sent)
+	}
+
+	nexmo.SMS.SkipDuplicateRecipients = false
+	for i, r := range nexmo.SMS.SendBatch(msgs[:3]) {
+		if r.Err != nil {
+			t.Errorf("Result %d without deduplication = %+v, want it sent", i, r)
+		}
+	}
+}
+
+func TestBatchRemainingBalance(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.PostForm.Get("to") {
+		case "447700900001":
+			w.Write([]byte(`{"message-count": "2", "messages": [
+				{"status": "0", "message-id": "0A00000001", "remaining-balance": "9.96670000"},
+				{"status": "0", "message-id": "0A00000002", "remaining-balance": "9.93340000"}]}`))
+		case "447700900002":
+			w.Write([]byte(`{"message-count": "1", "messages": [
+				{"status": "0", "message-id": "0A00000003", "remaining-balance": "9.90010000"}]}`))
+		case "447700900003":
+			w.Write([]byte(`{"message-count": "1", "messages": [{"status": "7", "error-text": "Number barred"}]}`))
+		}
+	}))
+
+	msgs := []*SMSMessage{
+		{From: "gonexmo", To: "447700900001", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "447700900002", Type: Text, Text: "Hello"},
+		{From: "gonexmo", To: "447700900003", Type: Text, Text: "Hello"},
+	}
+	results := nexmo.SMS.SendBatch(msgs)
+	if results[0].RemainingBalance != 9.9334 {
+		t.Errorf("RemainingBalance of a two part message = %v, want that of the last part", results[0].RemainingBalance)
+	}
+	if balance, ok := BatchRemainingBalance(results); !ok || balance != 9.9001 {
+		t.Errorf("BatchRemainingBalance = %v, %v, want 9.9001", balance, ok)
+	}
+	if _, ok := BatchRemainingBalance(results[2:]); ok {
+		t.Error("BatchRemainingBalance reported a balance for a batch without any")
+	}
+}
+
+func TestSendBatchStreamContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var sent int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+
+	msgs := make([]*SMSMessage, 5)
+	for i := range msgs {
+		msgs[i] = &SMSMessage{From: "gonexmo", To: "447700900001", Type: Text, Text: "Hello"}
+	}
+	errs := make([]error, len(msgs))
+	var calls int
+	nexmo.SMS.SendBatchStreamContext(ctx, msgs, func(index int, resp *MessageResponse, err error) {
+		calls++
+		errs[index] = err
+		cancel()
+	})
+
+	if calls != len(msgs) {
+		t.Errorf("fn was called %d times, want once for each of %d messages", calls, len(msgs))
+	}
+	if sent != 1 || errs[0] != nil {
+		t.Errorf("Sent %d messages, first error %v, want only the first sent", sent, errs[0])
+	}
+	for i, err := range errs[1:] {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Message %d after cancellation got %v, want context.Canceled", i+1, err)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[int]int{}
+	inFlight, maxInFlight := 0, 0
+	runBatch(20, 3, func(i int) {
+		mu.Lock()
+		seen[i]++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+
+	if len(seen) != 20 {
+		t.Errorf("runBatch called fn with %d indexes, want 20", len(seen))
+	}
+	for i, n := range seen {
+		if n != 1 {
+			t.Errorf("runBatch called fn with %d %d times", i, n)
+		}
+	}
+	if maxInFlight > 3 {
+		t.Errorf("runBatch ran %d calls at once, want at most 3", maxInFlight)
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	failed := []bool{false, true, false, true}
+	err := batchError(len(failed), "lookups", func(i int) bool { return failed[i] })
+	if err == nil || err.Error() != "2 of 4 lookups failed" {
+		t.Errorf("batchError = %v, want 2 of 4 lookups failed", err)
+	}
+	if err := batchError(2, "lookups", func(int) bool { return false }); err != nil {
+		t.Errorf("batchError with no failures = %v, want nil", err)
+	}
+}
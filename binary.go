@@ -0,0 +1,127 @@
+package nexmo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BinaryPreset is a common combination of settings for binary messages, such
+// as SIM OTA updates and WAP Push.
+//
+// Nexmo has no data coding scheme parameter: binary messages are always sent
+// with 8-bit data coding, and the message class bits of the DCS come from
+// message-class. A preset therefore picks the message class, protocol ID and
+// UDH that give the DCS and routing the scenario needs.
+type BinaryPreset int
+
+const (
+	// Binary8BitFlash is 8-bit data shown immediately (DCS 0xF4).
+	Binary8BitFlash BinaryPreset = iota
+
+	// Binary8BitClass1 is 8-bit data for the handset (DCS 0xF5).
+	Binary8BitClass1
+
+	// BinarySIMDataDownload is a GSM 03.48 command packet for the SIM (DCS
+	// 0xF6, PID 0x7F SIM data download, UDH IEI 0x70).
+	BinarySIMDataDownload
+
+	// BinaryWAPPush is a WAP Push or OMA Client Provisioning message for the
+	// handset's WAP port 2948 (DCS 0xF5, UDH 16-bit port addressing).
+	BinaryWAPPush
+)
+
+// Common values of SMSMessage.ProtocolID, the GSM 03.40 TP-PID. Zero, the
+// default, is a plain short message and isn't sent. Some carriers require a
+// particular protocol ID for WAP Push Service Indication or Service Loading
+// messages to be shown as a link on older handsets; they say which.
+const (
+	ProtocolIDType0           = 0x40 // Acknowledged by the handset but discarded, so never shown.
+	ProtocolIDReplaceType1    = 0x41 // Replaces an earlier type 1 message from the same sender; 0x42 to 0x47 are types 2 to 7.
+	ProtocolIDReturnCall      = 0x5F // Return call message.
+	ProtocolIDMEDataDownload  = 0x7D // Data for the handset itself.
+	ProtocolIDSIMDataDownload = 0x7F // Data for the SIM, as used by BinarySIMDataDownload.
+)
+
+var binaryPresets = map[BinaryPreset]struct {
+	class      MessageClass
+	protocolID int
+	udh        []byte
+}{
+	Binary8BitFlash:       {Flash, 0, nil},
+	Binary8BitClass1:      {Standard, 0, nil},
+	BinarySIMDataDownload: {SIMData, ProtocolIDSIMDataDownload, []byte{0x02, 0x70, 0x00}},
+	BinaryWAPPush:         {Standard, 0, []byte{0x06, 0x05, 0x04, 0x0B, 0x84, 0x23, 0xF0}},
+}
+
+// SetBinary makes msg a binary message carrying body, with the message class,
+// protocol ID and UDH of preset. A preset without a UDH of its own keeps any
+// UDH already set on msg.
+func (msg *SMSMessage) SetBinary(preset BinaryPreset, body []byte) {
+	p := binaryPresets[preset]
+	msg.Type = Binary
+	msg.Body = body
+	msg.Class = p.class
+	msg.ProtocolID = p.protocolID
+	if p.udh != nil {
+		msg.UDH = append([]byte(nil), p.udh...)
+	}
+}
+
+// MaxBinaryLength is the most bytes of UDH and body a single binary message
+// can carry.
+const MaxBinaryLength = 140
+
+// concatUDHLength is the size of the 8-bit reference concatenation
+// information element a split message adds to the UDH of every part.
+const concatUDHLength = 5
+
+// SplitBinary splits the binary message msg, whose UDH and body together
+// exceed MaxBinaryLength, into parts the handset reassembles using ref, a
+// reference that must differ from that of other recent split messages to
+// the same number. Every part keeps the information elements of msg.UDH,
+// such as WAP port addressing, and adds a concatenation element, so with no
+// UDH of its own each part carries 134 bytes of body. A message that fits
+// is returned as is.
+func (msg *SMSMessage) SplitBinary(ref byte) ([]*SMSMessage, error) {
+	if msg.Type != Binary {
+		return nil, errors.New("Only binary messages can be split")
+	}
+	if len(msg.UDH)+len(msg.Body) <= MaxBinaryLength {
+		return []*SMSMessage{msg}, nil
+	}
+
+	var elements []byte
+	if len(msg.UDH) > 0 {
+		if int(msg.UDH[0]) != len(msg.UDH)-1 {
+			return nil, errors.New("UDH length byte does not match the UDH")
+		}
+		elements = msg.UDH[1:]
+	}
+	udhLength := 1 + len(elements) + concatUDHLength
+	size := MaxBinaryLength - udhLength
+	if size <= 0 {
+		return nil, errors.New("UDH leaves no room for a body")
+	}
+	total := (len(msg.Body) + size - 1) / size
+	if total > 255 {
+		return nil, fmt.Errorf("Binary message needs %d parts, more than the maximum of 255", total)
+	}
+
+	parts := make([]*SMSMessage, total)
+	for i := range parts {
+		end := (i + 1) * size
+		if end > len(msg.Body) {
+			end = len(msg.Body)
+		}
+		udh := make([]byte, 0, udhLength)
+		udh = append(udh, byte(udhLength-1))
+		udh = append(udh, elements...)
+		udh = append(udh, 0x00, 0x03, ref, byte(total), byte(i+1))
+
+		part := *msg
+		part.UDH = udh
+		part.Body = append([]byte(nil), msg.Body[i*size:end]...)
+		parts[i] = &part
+	}
+	return parts, nil
+}
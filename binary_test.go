@@ -0,0 +1,172 @@
+package nexmo
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSetBinary(t *testing.T) {
+	msg := &SMSMessage{}
+	msg.SetBinary(BinarySIMDataDownload, []byte{0xAB, 0xCD})
+
+	vals := msg.ToValues()
+	want := map[string]string{
+		"type":          Binary,
+		"body":          "abcd",
+		"udh":           "027000",
+		"message-class": "2",
+		"protocol-id":   "127",
+	}
+	for name, value := range want {
+		if got := vals.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestBinaryMessageJSON(t *testing.T) {
+	msg := &SMSMessage{}
+	msg.SetBinary(Binary8BitFlash, []byte{0x01})
+	msg.UDH = []byte{0x00}
+
+	b, err := msg.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON failed with error:", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal("Unmarshal failed with error:", err)
+	}
+	if got["body"] != "01" || got["udh"] != "00" {
+		t.Errorf("Body and UDH not hex encoded: %s", b)
+	}
+	if got["message-class"] != 0.0 {
+		t.Errorf("Flash not encoded as message-class 0: %s", b)
+	}
+}
+
+func TestWAPPushValues(t *testing.T) {
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: WAPPush,
+		Title: "Offer", URL: "https://example.com/offer", Validity: 86400000,
+		ProtocolID: ProtocolIDMEDataDownload}
+
+	vals := msg.ToValues()
+	want := map[string]string{
+		"type":        WAPPush,
+		"title":       "Offer",
+		"url":         "https://example.com/offer",
+		"validity":    "86400000",
+		"protocol-id": "125",
+	}
+	for name, value := range want {
+		if got := vals.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestSetWAPPush(t *testing.T) {
+	msg := &SMSMessage{}
+	if err := msg.SetWAPPush(WAPPushSI, "Offer", "https://example.com/offer"); err != nil {
+		t.Fatal("SetWAPPush failed with error:", err)
+	}
+	if msg.Type != WAPPush || msg.Title != "Offer" || msg.URL != "https://example.com/offer" {
+		t.Errorf("Unexpected Service Indication %+v", msg)
+	}
+
+	msg = &SMSMessage{}
+	if err := msg.SetWAPPush(WAPPushSL, "", "https://www.example.com/a"); err != nil {
+		t.Fatal("SetWAPPush failed with error:", err)
+	}
+	want := []byte{0x01, 0x06, 0x01, 0xB0, 0x02, 0x06, 0x6A, 0x00, 0x85, 0x0C, 0x03,
+		'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm', '/', 'a', 0x00, 0x06, 0x01}
+	if msg.Type != Binary || !bytes.Equal(msg.Body, want) {
+		t.Errorf("Service Loading body = %x, want %x", msg.Body, want)
+	}
+	if !bytes.Equal(msg.UDH, binaryPresets[BinaryWAPPush].udh) {
+		t.Errorf("Service Loading UDH = %x, want WAP port addressing", msg.UDH)
+	}
+
+	if err := msg.SetWAPPush(WAPPushSI, "", "https://example.com"); err == nil {
+		t.Error("Expected an error for a Service Indication without a title")
+	}
+	if err := msg.SetWAPPush(WAPPushSL, "", ""); err == nil {
+		t.Error("Expected an error without a URL")
+	}
+}
+
+// reassembleBinary joins the parts of a split binary message as a handset
+// would, from the concatenation element of each part's UDH, and returns the
+// body and the other information elements.
+func reassembleBinary(t *testing.T, parts []*SMSMessage) ([]byte, []byte) {
+	bodies := make([][]byte, len(parts))
+	var others []byte
+	for _, part := range parts {
+		if n := len(part.UDH) + len(part.Body); n > MaxBinaryLength {
+			t.Errorf("Part is %d bytes, more than %d", n, MaxBinaryLength)
+		}
+		udh := part.UDH[1:]
+		if int(part.UDH[0]) != len(udh) {
+			t.Fatalf("UDH length %d does not match UDH %x", part.UDH[0], part.UDH)
+		}
+		var elements []byte
+		for len(udh) > 0 {
+			iei, length := udh[0], int(udh[1])
+			if iei == 0x00 {
+				total, seq := int(udh[3]), int(udh[4])
+				if total != len(parts) || seq < 1 || seq > total || bodies[seq-1] != nil {
+					t.Fatalf("Bad concatenation element %x", udh[:2+length])
+				}
+				bodies[seq-1] = part.Body
+			} else {
+				elements = append(elements, udh[:2+length]...)
+			}
+			udh = udh[2+length:]
+		}
+		others = elements
+	}
+	return bytes.Join(bodies, nil), others
+}
+
+func TestSplitBinary(t *testing.T) {
+	body := make([]byte, 300)
+	for i := range body {
+		body[i] = byte(i)
+	}
+
+	msg := &SMSMessage{Type: Binary, Body: body}
+	parts, err := msg.SplitBinary(0x42)
+	if err != nil {
+		t.Fatal("SplitBinary failed with error:", err)
+	}
+	if len(parts) != 3 || len(parts[0].Body) != 134 || len(parts[2].Body) != 32 {
+		t.Errorf("Split 300 bytes into %d parts, want 134, 134 and 32 bytes", len(parts))
+	}
+	if got, _ := reassembleBinary(t, parts); !bytes.Equal(got, body) {
+		t.Error("Reassembled body does not match")
+	}
+
+	msg = &SMSMessage{}
+	msg.SetBinary(BinaryWAPPush, body)
+	parts, err = msg.SplitBinary(0x43)
+	if err != nil {
+		t.Fatal("SplitBinary failed with error:", err)
+	}
+	got, others := reassembleBinary(t, parts)
+	if !bytes.Equal(got, body) {
+		t.Error("Reassembled WAP Push body does not match")
+	}
+	if !bytes.Equal(others, msg.UDH[1:]) {
+		t.Errorf("Parts lost the port addressing: got %x, want %x", others, msg.UDH[1:])
+	}
+	if len(parts[0].Body) != 128 {
+		t.Errorf("WAP Push part carries %d bytes, want 128", len(parts[0].Body))
+	}
+
+	short := &SMSMessage{Type: Binary, UDH: []byte{0x00}, Body: []byte{0x01}}
+	if parts, err := short.SplitBinary(0); err != nil || len(parts) != 1 || parts[0] != short {
+		t.Errorf("SplitBinary of a short message = %v, %v, want it as is", parts, err)
+	}
+}
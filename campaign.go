@@ -0,0 +1,89 @@
+package nexmo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Campaign is a mail-merge style send of one templated text message to many
+// recipients, for SMS.SendCampaign.
+type Campaign struct {
+	From string
+
+	// Template is the message text, with {{name}} placeholders replaced by
+	// each recipient's Vars.
+	Template string
+
+	Recipients []CampaignRecipient
+
+	// Progress, if set, is called after each recipient is handled with the
+	// number handled so far and the total. Calls are never concurrent.
+	Progress func(done, total int)
+}
+
+// CampaignRecipient is a recipient of a Campaign and the values of the
+// template's placeholders for them.
+type CampaignRecipient struct {
+	To   string
+	Vars map[string]string
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// placeholders returns the names of the placeholders in template.
+func placeholders(template string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// Render returns the campaign's text for r, or an error if r has no value for
+// one of the placeholders.
+func (campaign *Campaign) Render(r CampaignRecipient) (string, error) {
+	for _, name := range placeholders(campaign.Template) {
+		if _, ok := r.Vars[name]; !ok {
+			return "", fmt.Errorf("Recipient %s has no value for {{%s}}", r.To, name)
+		}
+	}
+	return placeholderPattern.ReplaceAllStringFunc(campaign.Template, func(placeholder string) string {
+		return r.Vars[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// SendCampaign renders the campaign's message for every recipient and sends
+// them with SendBatchStream, so BatchConcurrency, MinBalance and
+// SkipDuplicateRecipients apply. The results are in the same order as the
+// recipients. Every message is rendered before any is sent, and if one
+// can't be, nothing is sent and the error says which. Otherwise the error
+// summarises any messages that failed.
+func (c *SMS) SendCampaign(campaign *Campaign) ([]SendResult, error) {
+	if strings.TrimSpace(campaign.Template) == "" {
+		return nil, errors.New("Invalid template specified")
+	}
+
+	msgs := make([]*SMSMessage, len(campaign.Recipients))
+	for i, r := range campaign.Recipients {
+		text, err := campaign.Render(r)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = &SMSMessage{From: campaign.From, To: r.To, Type: Text, Text: text}
+	}
+
+	results := make([]SendResult, len(msgs))
+	done := 0
+	c.SendBatchStream(msgs, func(index int, resp *MessageResponse, err error) {
+		results[index] = newSendResult(msgs[index].To, resp, err)
+		done++
+		if campaign.Progress != nil {
+			campaign.Progress(done, len(msgs))
+		}
+	})
+	return results, batchError(len(results), "campaign messages", func(i int) bool {
+		return results[i].Err != nil
+	})
+}
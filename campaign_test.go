@@ -0,0 +1,58 @@
+package nexmo
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSendCampaign(t *testing.T) {
+	texts := map[string]string{}
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		texts[r.PostForm.Get("to")] = r.PostForm.Get("text")
+		if r.PostForm.Get("to") == "447700900002" {
+			w.Write([]byte(`{"message-count": "1", "messages": [{"status": "7", "error-text": "Number barred"}]}`))
+			return
+		}
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+
+	var progress []int
+	campaign := &Campaign{
+		From:     "gonexmo",
+		Template: "Hi {{name}}, your code is {{ code }}.",
+		Recipients: []CampaignRecipient{
+			{To: "447700900001", Vars: map[string]string{"name": "Ann", "code": "A1"}},
+			{To: "447700900002", Vars: map[string]string{"name": "Bob", "code": "B2"}},
+		},
+		Progress: func(done, total int) {
+			if total != 2 {
+				t.Errorf("Progress total = %d, want 2", total)
+			}
+			progress = append(progress, done)
+		},
+	}
+
+	results, err := nexmo.SMS.SendCampaign(campaign)
+	if err == nil {
+		t.Error("Expected an error summarising the failed message")
+	}
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("Unexpected results %+v", results)
+	}
+	if got := texts["447700900001"]; got != "Hi Ann, your code is A1." {
+		t.Errorf("Rendered %q", got)
+	}
+	if len(progress) != 2 || progress[1] != 2 {
+		t.Errorf("Progress = %v, want 1 then 2", progress)
+	}
+
+	texts = map[string]string{}
+	campaign.Recipients = append(campaign.Recipients, CampaignRecipient{To: "447700900003", Vars: map[string]string{"name": "Cat"}})
+	if _, err := nexmo.SMS.SendCampaign(campaign); err == nil {
+		t.Error("Expected an error for a recipient without every placeholder")
+	}
+	if len(texts) != 0 {
+		t.Errorf("Sent %d messages of a campaign that failed to render, want none", len(texts))
+	}
+}
@@ -2,6 +2,13 @@ package nexmo
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Client encapsulates the Nexmo functions - must be created with
@@ -11,10 +18,87 @@ type Client struct {
 	SMS            *SMS
 	Numbers        *Numbers
 	USSD           *USSD
+	Insight        *Insight
+	Verify         *Verify
+	Reports        *Reports
 	apiKey         string
 	apiSecret      string
 	useOauth       bool
+	clock          clock
+	httpClient     *http.Client
+	restURL        string
+	apiURL         string
 	VerboseLogging bool
+
+	// Logger receives verbose logging. Defaults to the standard logger.
+	Logger Logger
+
+	// ReferenceDataTTL is how long reference data such as pricing is cached
+	// for. Defaults to DefaultReferenceDataTTL.
+	ReferenceDataTTL time.Duration
+	refData          referenceData
+
+	// Location, if set, is the time zone of the times in reports, secrets,
+	// and the delivery receipts and inbound messages passed through
+	// SMS.NewDeliveryHandler and SMS.NewMessageHandler. Times Nexmo sends
+	// without a zone are read in Location, and those with one are converted
+	// to it. Defaults to UTC.
+	Location *time.Location
+
+	// Headers are added to every request sent to Nexmo, e.g. for an API
+	// gateway in front of Nexmo. They can not replace the headers the
+	// package sets itself, such as Accept or Content-Type.
+	Headers http.Header
+
+	// MaxConcurrent limits the number of requests to Nexmo in flight at
+	// once, across all services. A request stays in flight until its
+	// response body is closed. Zero means no limit. It must be set before
+	// the first request.
+	MaxConcurrent int
+	semOnce       sync.Once
+	sem           chan struct{}
+
+	// Backoff decides whether and when to retry a request that failed with
+	// HTTP 429, or a GET request that failed with a network error or a 5xx
+	// status. Nil, the default, never retries. Other requests, such as
+	// buying a number or starting a verification, may have been carried
+	// out before failing, so they aren't repeated; nor is an SMS send,
+	// unless the message has an IdempotencyKey.
+	Backoff BackoffStrategy
+
+	// RetryPredicate decides whether a sent SMS is retried, replacing the
+	// default classification, Retryable. It is consulted for every attempt
+	// that isn't retried as a failed request. Messages are only retried, as
+	// often and as late as Backoff says, when Backoff is set, and the
+	// attempts count towards the same limit whatever the reason.
+	RetryPredicate func(resp *MessageResponse, err error) bool
+
+	// RequestInterceptor, if set, is called just before every request is
+	// sent, including each retry, and may log or change it. It sees the
+	// request complete, with credentials, Accept, Content-Type and Headers
+	// already set, and may override any of them. The User-Agent, if not set,
+	// is added by net/http afterwards. Returning an error aborts the request
+	// with that error.
+	RequestInterceptor func(*http.Request) error
+
+	// XML makes SMS sends use Nexmo's XML interface instead of JSON, for
+	// integrations that log or proxy the raw XML. The decoded
+	// MessageResponse is the same either way. Nexmo's other APIs only
+	// respond in JSON, so they are unaffected.
+	XML bool
+
+	// OnRequest and OnResponse, if set, are called with a structured
+	// description of every request sent to Nexmo, including each retry,
+	// and of its outcome, e.g. to feed an event bus. Credentials are always
+	// redacted from the events. They are called synchronously, so must be
+	// quick.
+	OnRequest  func(RequestEvent)
+	OnResponse func(ResponseEvent)
+
+	// OnWarning, if set, is called with each soft issue found in a response,
+	// e.g. to log signs of API drift. The warnings are also collected in
+	// MessageResponse.Warnings.
+	OnWarning func(Warning)
 }
 
 // NewClientFromAPI creates a new Client type with the
@@ -27,14 +111,233 @@ func NewClientFromAPI(apiKey, apiSecret string) (*Client, error) {
 	}
 
 	c := &Client{
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		useOauth:  false,
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		useOauth:   false,
+		clock:      realClock{},
+		httpClient: &http.Client{},
+		restURL:    apiRoot,
+		apiURL:     apiRootv2,
 	}
 
-	c.Account = &Account{c}
-	c.SMS = &SMS{c}
-	c.Numbers = &Numbers{c}
+	c.Account = &Account{client: c}
+	c.SMS = &SMS{
+		client:           c,
+		IdempotencyStore: newMemoryIdempotencyStore(24*time.Hour, func() time.Time { return c.clock.Now() }),
+	}
+	c.Numbers = &Numbers{client: c}
 	c.USSD = &USSD{c}
+	c.Insight = &Insight{client: c}
+	c.Verify = &Verify{c}
+	c.Reports = &Reports{c}
 	return c, nil
 }
+
+// protectedHeaders can not be set through Client.Headers.
+var protectedHeaders = []string{"Accept", "Content-Type", "User-Agent", "Authorization"}
+
+// do sends r to Nexmo, retrying as Backoff allows. Every request made by the
+// package goes through here or doBackoff. Requests accept JSON unless they
+// set Accept themselves.
+func (c *Client) do(r *http.Request) (*http.Response, error) {
+	return c.doBackoff(r, c.Backoff)
+}
+
+// doBackoff is like do, but retries as backoff allows, or not at all if
+// backoff is nil.
+func (c *Client) doBackoff(r *http.Request, backoff BackoffStrategy) (*http.Response, error) {
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", "application/json")
+	}
+	for name, values := range c.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if r.Header.Get(name) != "" || isProtectedHeader(name) {
+			continue
+		}
+		for _, value := range values {
+			r.Header.Add(name, value)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(r, attempt)
+
+		retryErr := err
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			retryErr = ErrThrottled
+		} else if err == nil && resp.StatusCode >= 500 {
+			retryErr = fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+		}
+		if retryErr != nil && retryErr != ErrThrottled && r.Method != http.MethodGet {
+			// Nexmo may have carried out the request before failing.
+			retryErr = nil
+		}
+		if retryErr == nil || backoff == nil || (r.Body != nil && r.GetBody == nil) {
+			return resp, err
+		}
+		delay, ok := backoff.NextDelay(attempt, retryErr)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-c.clock.After(delay):
+		case <-r.Context().Done():
+			return nil, c.requestError(r, r.Context().Err())
+		}
+		if r.GetBody != nil {
+			if r.Body, err = r.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// attempt sends r to Nexmo once, as attempt number n.
+func (c *Client) attempt(r *http.Request, n int) (*http.Response, error) {
+	if c.RequestInterceptor != nil {
+		if err := c.RequestInterceptor(r); err != nil {
+			return nil, c.requestError(r, err)
+		}
+	}
+
+	if c.OnRequest == nil && c.OnResponse == nil {
+		return c.roundTrip(r)
+	}
+	event := c.requestEvent(r, n)
+	if c.OnRequest != nil {
+		c.OnRequest(event)
+	}
+	start := c.clock.Now()
+	resp, err := c.roundTrip(r)
+	if c.OnResponse != nil {
+		response := ResponseEvent{RequestEvent: event, Latency: c.clock.Now().Sub(start), Err: err}
+		if resp != nil {
+			response.StatusCode = resp.StatusCode
+			response.NexmoStatus = nexmoStatus(resp)
+		}
+		c.OnResponse(response)
+	}
+	return resp, err
+}
+
+// roundTrip sends r once, holding a request slot until the response body
+// is closed.
+func (c *Client) roundTrip(r *http.Request) (*http.Response, error) {
+	release, err := c.acquire(r)
+	if err != nil {
+		return nil, c.requestError(r, err)
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		release()
+		endpoint := c.redactURL(r.URL)
+		if urlErr, ok := err.(*url.Error); ok {
+			// The original URL may contain the API secret.
+			urlErr.URL = endpoint
+		}
+		return nil, &RequestError{Method: r.Method, Endpoint: endpoint, Err: err}
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// acquire waits for a free request slot when MaxConcurrent is set, or until
+// r is cancelled. The returned func gives the slot back.
+func (c *Client) acquire(r *http.Request) (func(), error) {
+	c.semOnce.Do(func() {
+		if c.MaxConcurrent > 0 {
+			c.sem = make(chan struct{}, c.MaxConcurrent)
+		}
+	})
+	if c.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.sem <- struct{}{}:
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-c.sem }) }, nil
+}
+
+// releasingBody gives back a request slot when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// redactURL returns u as a string with the API key and secret removed, so it
+// can be safely logged or included in errors.
+func (c *Client) redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	segments := strings.Split(redacted.Path, "/")
+	for i, segment := range segments {
+		if segment == c.apiSecret {
+			segments[i] = "REDACTED"
+		}
+	}
+	redacted.Path = strings.Join(segments, "/")
+	redacted.RawPath = ""
+
+	query := redacted.Query()
+	if query.Get("api_secret") != "" {
+		query.Set("api_secret", "REDACTED")
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// RequestError is the error of a request to Nexmo, naming the request.
+// Every error returned after a request was made is one, whether sending it
+// failed or Nexmo's response reported a failure.
+type RequestError struct {
+	Method   string
+	Endpoint string // With the API secret redacted.
+	Err      error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("nexmo: %s %s failed: %v", e.Method, e.Endpoint, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// requestError wraps err, the failure of r, in a RequestError.
+func (c *Client) requestError(r *http.Request, err error) error {
+	return &RequestError{Method: r.Method, Endpoint: c.redactURL(r.URL), Err: err}
+}
+
+// wrapError wraps *err, if set and not already a RequestError, in a
+// RequestError for r. Service methods defer it once their request is built,
+// so that all their errors name the request.
+func (c *Client) wrapError(r *http.Request, err *error) {
+	var requestErr *RequestError
+	if *err != nil && !errors.As(*err, &requestErr) {
+		*err = c.requestError(r, *err)
+	}
+}
+
+func isProtectedHeader(name string) bool {
+	for _, protected := range protectedHeaders {
+		if name == protected {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientHeaders(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer ts.Close()
+
+	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	if err != nil {
+		t.Fatal("Failed to create Client with error:", err)
+	}
+	nexmo.Headers = http.Header{}
+	nexmo.Headers.Set("X-Api-Gateway-Key", "gateway")
+	nexmo.Headers.Set("Accept", "text/html")
+
+	r, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := nexmo.do(r)
+	if err != nil {
+		t.Fatal("Request failed with error:", err)
+	}
+	resp.Body.Close()
+
+	if got.Get("X-Api-Gateway-Key") != "gateway" {
+		t.Error("Custom header was not sent")
+	}
+	if got.Get("Accept") != "application/json" {
+		t.Error("Custom header replaced Accept, got:", got.Get("Accept"))
+	}
+}
+
+func TestClientErrorRedactsSecret(t *testing.T) {
+	nexmo, err := NewClientFromAPI("key", "supersecret")
+	if err != nil {
+		t.Fatal("Failed to create Client with error:", err)
+	}
+
+	r, _ := http.NewRequest("GET", "http://127.0.0.1:0/number/buy/key/supersecret/US/1?api_secret=supersecret", nil)
+	_, err = nexmo.do(r)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if strings.Contains(err.Error(), "supersecret") {
+		t.Error("Error contains the API secret:", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Error("Error does not wrap the transport error:", err)
+	}
+}
+
+func TestServiceErrorsIncludeRequest(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/verify/") {
+			fmt.Fprint(w, `{"status": "3", "error_text": "Invalid value"}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	nexmo.apiSecret = "supersecret"
+
+	tests := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{"GetBalance", func() error { _, err := nexmo.Account.GetBalance(); return err }, "GET "},
+		{"ListSecrets", func() error { _, err := nexmo.Account.ListSecrets(); return err }, "GET "},
+		{"Insight", func() error { _, err := nexmo.Insight.Standard("447700900000"); return err }, "GET "},
+		{"VerifyCheck", func() error { _, err := nexmo.Verify.Check("abcdef", "1234"); return err }, "POST "},
+	}
+	for _, tt := range tests {
+		err := tt.call()
+		var requestErr *RequestError
+		if !errors.As(err, &requestErr) {
+			t.Errorf("%s: error %v is not a *RequestError", tt.name, err)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "nexmo: "+tt.want) {
+			t.Errorf("%s: error %q does not name the method and endpoint", tt.name, err)
+		}
+		if strings.Contains(err.Error(), "supersecret") {
+			t.Errorf("%s: error contains the API secret: %v", tt.name, err)
+		}
+	}
+}
+
+func TestClientMaxConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	unblock := make(chan struct{})
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		<-unblock
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	nexmo.MaxConcurrent = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, _ := http.NewRequest("GET", nexmo.restURL, nil)
+			resp, err := nexmo.do(r)
+			if err != nil {
+				t.Error("Request failed with error:", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	// Give every request the chance to reach the server before unblocking.
+	for {
+		mu.Lock()
+		n := inFlight
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("%d requests were in flight at once, want at most 2", maxInFlight)
+	}
+	if len(nexmo.sem) != 0 {
+		t.Errorf("%d request slots were not given back", len(nexmo.sem))
+	}
+}
+
+func TestClientRequestInterceptor(t *testing.T) {
+	var got http.Header
+	var calls int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		got = r.Header
+	}))
+	nexmo.Headers = http.Header{"X-Api-Gateway-Key": {"gateway"}}
+
+	var seen http.Header
+	nexmo.RequestInterceptor = func(r *http.Request) error {
+		seen = r.Header.Clone()
+		r.Header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		return nil
+	}
+
+	r, _ := http.NewRequest("GET", nexmo.restURL, nil)
+	resp, err := nexmo.do(r)
+	if err != nil {
+		t.Fatal("Request failed with error:", err)
+	}
+	resp.Body.Close()
+
+	if seen.Get("Accept") != "application/json" || seen.Get("X-Api-Gateway-Key") != "gateway" {
+		t.Errorf("Interceptor saw headers %v, want Accept and Headers already set", seen)
+	}
+	if got.Get("Traceparent") == "" {
+		t.Error("Header set by the interceptor was not sent")
+	}
+
+	abort := errors.New("aborted")
+	nexmo.RequestInterceptor = func(r *http.Request) error { return abort }
+	calls = 0
+	r, _ = http.NewRequest("GET", nexmo.restURL, nil)
+	if _, err := nexmo.do(r); !errors.Is(err, abort) || calls != 0 {
+		t.Errorf("Request with a failing interceptor = %v after %d calls, want the interceptor's error", err, calls)
+	}
+}
+
+func TestClientEvents(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message-count": "2", "messages": [{"status": "0", "message-id": "0A00000001"},
+			{"status": "9", "error-text": "Partner quota exceeded"}]}`))
+	}))
+	nexmo.apiSecret = "supersecret"
+
+	var requests []RequestEvent
+	var responses []ResponseEvent
+	nexmo.OnRequest = func(e RequestEvent) { requests = append(requests, e) }
+	nexmo.OnResponse = func(e ResponseEvent) { responses = append(responses, e) }
+
+	ctx := WithLogger(context.Background(), log.New(ioutil.Discard, "", 0), "trace-1")
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello",
+		Metadata: map[string]string{"campaign": "spring"}}
+	sent, err := nexmo.SMS.SendContext(ctx, msg)
+	if err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	if len(sent.Messages) != 2 {
+		t.Errorf("Send decoded %d message reports, want 2", len(sent.Messages))
+	}
+
+	if len(requests) != 1 || len(responses) != 1 {
+		t.Fatalf("Got %d request and %d response events, want 1 of each", len(requests), len(responses))
+	}
+	req, resp := requests[0], responses[0]
+	if req.Method != "POST" || req.TraceID != "trace-1" || req.Attempt != 1 {
+		t.Errorf("Unexpected request event %+v", req)
+	}
+	if req.Params.Get("to") != "447700900000" || req.Params.Get("api_key") != API_KEY {
+		t.Errorf("Request event is missing the form parameters: %v", req.Params)
+	}
+	if req.Params.Get("api_secret") != "REDACTED" {
+		t.Errorf("api_secret = %q, want it redacted", req.Params.Get("api_secret"))
+	}
+	if req.CorrelationID == "" || req.CorrelationID != sent.CorrelationID {
+		t.Errorf("Request event correlation ID = %q, want %q", req.CorrelationID, sent.CorrelationID)
+	}
+	if req.Metadata["campaign"] != "spring" || resp.Metadata["campaign"] != "spring" {
+		t.Errorf("Event metadata %v and %v, want the message's", req.Metadata, resp.Metadata)
+	}
+	if _, ok := req.Params["campaign"]; ok {
+		t.Errorf("Metadata was sent in the form: %v", req.Params)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Err != nil || resp.TraceID != "trace-1" {
+		t.Errorf("Unexpected response event %+v", resp)
+	}
+	if resp.CorrelationID != sent.CorrelationID || resp.NexmoStatus != "9" {
+		t.Errorf("Response event correlation ID %q and Nexmo status %q, want %q and 9",
+			resp.CorrelationID, resp.NexmoStatus, sent.CorrelationID)
+	}
+	for _, e := range []interface{}{req, resp} {
+		if dump := fmt.Sprintf("%+v", e); strings.Contains(dump, "supersecret") {
+			t.Error("Event contains the API secret:", dump)
+		}
+	}
+}
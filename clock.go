@@ -0,0 +1,31 @@
+package nexmo
+
+import "time"
+
+// clock is the source of time for everything in the package that waits or
+// schedules, so tests can substitute a fake one.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) timer
+}
+
+// timer is the subset of *time.Timer used by the package.
+type timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// realClock is the default clock, backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) timer         { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+func (t realTimer) Stop() bool          { return t.t.Stop() }
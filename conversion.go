@@ -0,0 +1,79 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// conversionTimeFormat is the timestamp format of the Conversion API, in UTC.
+const conversionTimeFormat = "2006-01-02 15:04:05"
+
+/*
+	POST https://api.nexmo.com/conversions/sms?api_key={api_key}&api_secret={api_secret}&message-id={message_id}&delivered={delivered}&timestamp={timestamp}
+*/
+
+// ReportConversion tells Nexmo's Conversion API whether the message with
+// messageID achieved its purpose, e.g. whether the code it carried was
+// used, and when. Nexmo uses conversions to route future messages over the
+// carriers that deliver best.
+func (c *SMS) ReportConversion(ctx context.Context, messageID string, delivered bool, at time.Time) (err error) {
+	if len(messageID) <= 0 {
+		return errors.New("Invalid message ID specified")
+	}
+
+	values := url.Values{}
+	values.Set("api_key", c.client.apiKey)
+	values.Set("api_secret", c.client.apiSecret)
+	values.Set("message-id", messageID)
+	values.Set("delivered", strconv.FormatBool(delivered))
+	values.Set("timestamp", at.UTC().Format(conversionTimeFormat))
+
+	r, _ := http.NewRequest("POST", c.client.apiURL+"/conversions/sms?"+values.Encode(), nil)
+	r = r.WithContext(ctx)
+
+	defer c.client.wrapError(r, &err)
+	resp, err := c.client.do(r)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// TrackedSend sends msg and waits for its delivery receipt as
+// SendAndWaitDelivery does, then reports the outcome to the Conversion API
+// for the first part of the message, so the whole lifecycle is tied to the
+// send's CorrelationID in the verbose log. The receipt must reach the
+// handler returned from c.NewDeliveryHandler, so that has to be serving
+// Nexmo's delivery receipt callback. If no final receipt arrives within
+// timeout, nothing is reported and ErrDeliveryTimeout is returned.
+func (c *SMS) TrackedSend(ctx context.Context, msg *SMSMessage, timeout time.Duration) (*MessageResponse, *DeliveryReceipt, error) {
+	messageResponse, receipt, err := c.SendAndWaitDelivery(ctx, msg, timeout)
+	if err != nil {
+		return messageResponse, receipt, err
+	}
+	if receipt == nil {
+		return messageResponse, nil, errors.New("Response had no messages to track")
+	}
+
+	messageID := messageResponse.Messages[0].MessageID
+	delivered := receipt.Status == DeliveryDelivered
+	at := receipt.Timestamp.Time
+	if at.IsZero() {
+		at = c.client.clock.Now()
+	}
+	c.client.logf(ctx, "[%s] Reporting conversion of %s, delivered: %t", messageResponse.CorrelationID, messageID, delivered)
+	if err := c.ReportConversion(ctx, messageID, delivered, at); err != nil {
+		return messageResponse, receipt, fmt.Errorf("Conversion report failed: %w", err)
+	}
+	return messageResponse, receipt, nil
+}
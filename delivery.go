@@ -0,0 +1,196 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Delivery receipt statuses.
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryExpired   = "expired"
+	DeliveryFailed    = "failed"
+	DeliveryRejected  = "rejected"
+	DeliveryAccepted  = "accepted"
+	DeliveryBuffered  = "buffered"
+	DeliveryUnknown   = "unknown"
+)
+
+// ErrDeliveryTimeout is returned by SendAndWaitDelivery when a delivery
+// receipt doesn't arrive in time.
+var ErrDeliveryTimeout = errors.New("Timed out waiting for delivery receipt")
+
+// isFinalDeliveryStatus reports whether no further receipts will follow one with status.
+func isFinalDeliveryStatus(status string) bool {
+	return status != DeliveryAccepted && status != DeliveryBuffered
+}
+
+// Receipts nobody is waiting for yet are held for earlyReceiptTTL, which is
+// ample time for Send to return the message ID, and at most
+// maxEarlyReceipts of them, dropping the oldest first.
+const (
+	earlyReceiptTTL  = time.Minute
+	maxEarlyReceipts = 1000
+)
+
+// receiptRegistry hands delivery receipts from the delivery handler to the
+// SendAndWaitDelivery calls waiting for them, correlated by message ID.
+type receiptRegistry struct {
+	mu      sync.Mutex
+	waiters map[string]chan *DeliveryReceipt
+
+	// Receipts can arrive before Send has returned the message ID to wait
+	// on. While any wait is in progress, unclaimed receipts are held here,
+	// oldest first.
+	early  []earlyReceipt
+	active int
+}
+
+type earlyReceipt struct {
+	receipt *DeliveryReceipt
+	at      time.Time
+}
+
+func (reg *receiptRegistry) begin() {
+	reg.mu.Lock()
+	reg.active++
+	reg.mu.Unlock()
+}
+
+func (reg *receiptRegistry) end() {
+	reg.mu.Lock()
+	reg.active--
+	if reg.active == 0 {
+		reg.early = nil
+	}
+	reg.mu.Unlock()
+}
+
+// wait returns a channel receiving the receipts for messageID.
+func (reg *receiptRegistry) wait(messageID string) <-chan *DeliveryReceipt {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	var held []*DeliveryReceipt
+	kept := reg.early[:0]
+	for _, e := range reg.early {
+		if e.receipt.MessageID == messageID {
+			held = append(held, e.receipt)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	reg.early = kept
+
+	// Room for the held receipts and those still to come. deliver drops
+	// receipts rather than block if it fills up.
+	ch := make(chan *DeliveryReceipt, len(held)+8)
+	for _, m := range held {
+		ch <- m
+	}
+
+	if reg.waiters == nil {
+		reg.waiters = make(map[string]chan *DeliveryReceipt)
+	}
+	reg.waiters[messageID] = ch
+	return ch
+}
+
+func (reg *receiptRegistry) forget(messageID string) {
+	reg.mu.Lock()
+	delete(reg.waiters, messageID)
+	reg.mu.Unlock()
+}
+
+// deliver passes m, received at now, to the wait for its message ID, or
+// holds it in case a wait for it is about to start.
+func (reg *receiptRegistry) deliver(m *DeliveryReceipt, now time.Time) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if ch, ok := reg.waiters[m.MessageID]; ok {
+		select {
+		case ch <- m:
+		default:
+		}
+		return
+	}
+	if reg.active == 0 {
+		return
+	}
+	expired := 0
+	for expired < len(reg.early) && now.Sub(reg.early[expired].at) > earlyReceiptTTL {
+		expired++
+	}
+	if len(reg.early)-expired >= maxEarlyReceipts {
+		expired = len(reg.early) - maxEarlyReceipts + 1
+	}
+	reg.early = append(reg.early[expired:], earlyReceipt{m, now})
+}
+
+// NewDeliveryHandler is like the package level NewDeliveryHandler, but also
+// passes each receipt to any SendAndWaitDelivery waiting for it, and reads
+// its times in Client.Location. out may be nil if the receipts are not
+// otherwise needed.
+func (c *SMS) NewDeliveryHandler(out chan *DeliveryReceipt, verifyIPs bool) http.HandlerFunc {
+	return newDeliveryHandler(func(m *DeliveryReceipt) {
+		m.SCTS = c.client.localTime(m.SCTS)
+		m.Timestamp = c.client.localTime(m.Timestamp)
+		c.receipts.deliver(m, c.client.clock.Now())
+		if out != nil {
+			out <- m
+		}
+	}, verifyIPs, c.client.location())
+}
+
+// SendAndWaitDelivery sends msg with a delivery receipt requested, then waits
+// for the final receipt of every part of the message. The receipt returned is
+// that of the last part, or of the first part that wasn't delivered.
+//
+// Receipts are only seen if Nexmo's delivery receipt callback is served by
+// the handler returned from c.NewDeliveryHandler. If the receipts don't
+// arrive within timeout, ErrDeliveryTimeout is returned along with the
+// response to the send.
+func (c *SMS) SendAndWaitDelivery(ctx context.Context, msg *SMSMessage, timeout time.Duration) (*MessageResponse, *DeliveryReceipt, error) {
+	c.receipts.begin()
+	defer c.receipts.end()
+
+	msg.RequestStatusReport = true
+	messageResponse, err := c.SendContext(ctx, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending := make(map[string]<-chan *DeliveryReceipt)
+	for _, report := range messageResponse.Messages {
+		if report.Status != ResponseSuccess {
+			return messageResponse, nil, fmt.Errorf("Message was not sent: %s", report.ErrorText)
+		}
+		pending[report.MessageID] = c.receipts.wait(report.MessageID)
+		defer c.receipts.forget(report.MessageID)
+	}
+
+	t := c.client.clock.NewTimer(timeout)
+	defer t.Stop()
+
+	var receipt *DeliveryReceipt
+	for messageID, ch := range pending {
+		for receipt == nil || receipt.MessageID != messageID || !isFinalDeliveryStatus(receipt.Status) {
+			select {
+			case receipt = <-ch:
+			case <-t.C():
+				return messageResponse, nil, ErrDeliveryTimeout
+			case <-ctx.Done():
+				return messageResponse, nil, ctx.Err()
+			}
+		}
+		if receipt.Status != DeliveryDelivered {
+			break
+		}
+	}
+	return messageResponse, receipt, nil
+}
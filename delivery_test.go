@@ -0,0 +1,180 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReceiptRegistry(t *testing.T) {
+	var reg receiptRegistry
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Receipts are dropped when nobody is waiting.
+	reg.deliver(&DeliveryReceipt{MessageID: "dropped"}, now)
+	reg.begin()
+	select {
+	case <-reg.wait("dropped"):
+		t.Error("Receipt delivered with no wait in progress was kept")
+	default:
+	}
+
+	// Receipts arriving before wait are held for it.
+	reg.deliver(&DeliveryReceipt{MessageID: "early", Status: DeliveryDelivered}, now)
+	ch := reg.wait("early")
+	select {
+	case m := <-ch:
+		if m.Status != DeliveryDelivered {
+			t.Error("Got wrong receipt:", m)
+		}
+	default:
+		t.Error("Early receipt was not held")
+	}
+
+	reg.deliver(&DeliveryReceipt{MessageID: "early", Status: DeliveryFailed}, now)
+	select {
+	case m := <-ch:
+		if m.Status != DeliveryFailed {
+			t.Error("Got wrong receipt:", m)
+		}
+	default:
+		t.Error("Receipt was not passed to waiter")
+	}
+
+	reg.forget("early")
+	reg.forget("dropped")
+	reg.end()
+	if reg.early != nil || len(reg.waiters) != 0 {
+		t.Error("Registry was not cleaned up")
+	}
+}
+
+func TestReceiptRegistryEarlyLimits(t *testing.T) {
+	var reg receiptRegistry
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	reg.begin()
+	defer reg.end()
+
+	// Unclaimed receipts expire.
+	reg.deliver(&DeliveryReceipt{MessageID: "stale"}, now)
+	reg.deliver(&DeliveryReceipt{MessageID: "fresh"}, now.Add(earlyReceiptTTL+time.Second))
+	if len(reg.early) != 1 || reg.early[0].receipt.MessageID != "fresh" {
+		t.Errorf("Held %d receipts after one expired, want only the fresh one", len(reg.early))
+	}
+
+	// And are bounded in number, dropping the oldest.
+	for i := 0; i < 2*maxEarlyReceipts; i++ {
+		reg.deliver(&DeliveryReceipt{MessageID: "many"}, now.Add(earlyReceiptTTL+time.Second))
+	}
+	if len(reg.early) != maxEarlyReceipts {
+		t.Errorf("Held %d receipts, want at most %d", len(reg.early), maxEarlyReceipts)
+	}
+
+	// Any number of early receipts for one message are handed over.
+	for i := 0; i < 20; i++ {
+		reg.deliver(&DeliveryReceipt{MessageID: "multipart"}, now.Add(earlyReceiptTTL+time.Second))
+	}
+	if ch := reg.wait("multipart"); len(ch) != 20 {
+		t.Errorf("wait got %d early receipts, want 20", len(ch))
+	}
+	reg.forget("multipart")
+}
+
+func TestSendAndWaitDelivery(t *testing.T) {
+	var handler http.HandlerFunc
+	receipt := func(messageID, status string) {
+		query := url.Values{"messageId": {messageID}, "status": {status}, "msisdn": {"447700900000"},
+			"scts": {"2001011230"}, "message-timestamp": {"2020-01-01 12:30:00"}}
+		handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/?"+query.Encode(), nil))
+	}
+	var receipts []string
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The receipts arrive before the send returns.
+		for _, r := range receipts {
+			parts := strings.SplitN(r, ":", 2)
+			receipt(parts[0], parts[1])
+		}
+		w.Write([]byte(`{"message-count": "2", "messages": [
+			{"status": "0", "message-id": "0A00000001"}, {"status": "0", "message-id": "0A00000002"}]}`))
+	}))
+	handler = nexmo.SMS.NewDeliveryHandler(nil, false)
+	msg := func() *SMSMessage {
+		return &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: strings.Repeat("a", 200)}
+	}
+
+	receipts = []string{"0A00000001:" + DeliveryAccepted, "0A00000001:" + DeliveryDelivered, "0A00000002:" + DeliveryDelivered}
+	_, got, err := nexmo.SMS.SendAndWaitDelivery(context.Background(), msg(), time.Minute)
+	if err != nil || got == nil || got.Status != DeliveryDelivered {
+		t.Fatalf("SendAndWaitDelivery = %+v, %v, want a delivered receipt", got, err)
+	}
+
+	receipts = []string{"0A00000001:" + DeliveryDelivered, "0A00000002:" + DeliveryFailed}
+	_, got, err = nexmo.SMS.SendAndWaitDelivery(context.Background(), msg(), time.Minute)
+	if err != nil || got == nil || got.MessageID != "0A00000002" || got.Status != DeliveryFailed {
+		t.Errorf("SendAndWaitDelivery = %+v, %v, want the failed part's receipt", got, err)
+	}
+
+	// Only one part is delivered, so the wait times out.
+	receipts = []string{"0A00000001:" + DeliveryDelivered}
+	done := make(chan error)
+	go func() {
+		_, _, err := nexmo.SMS.SendAndWaitDelivery(context.Background(), msg(), time.Minute)
+		done <- err
+	}()
+	for {
+		select {
+		case err := <-done:
+			if err != ErrDeliveryTimeout {
+				t.Errorf("SendAndWaitDelivery with a missing receipt = %v, want ErrDeliveryTimeout", err)
+			}
+			return
+		case <-time.After(time.Millisecond):
+			clk.Advance(time.Minute)
+		}
+	}
+}
+
+func TestTrackedSend(t *testing.T) {
+	var nexmo *Client
+	var conversion url.Values
+	conversionStatus := http.StatusOK
+	nexmo, _ = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/conversions/sms" {
+			conversion = r.URL.Query()
+			w.WriteHeader(conversionStatus)
+			return
+		}
+		// The receipt may arrive before the send returns.
+		nexmo.SMS.receipts.deliver(&DeliveryReceipt{MessageID: "0A00000001", Status: DeliveryDelivered,
+			Timestamp: NexmoTime{time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)}}, nexmo.clock.Now())
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	_, receipt, err := nexmo.SMS.TrackedSend(context.Background(), msg, time.Minute)
+	if err != nil {
+		t.Fatal("TrackedSend failed with error:", err)
+	}
+	if receipt.Status != DeliveryDelivered {
+		t.Errorf("Receipt status = %q, want delivered", receipt.Status)
+	}
+	want := map[string]string{"message-id": "0A00000001", "delivered": "true", "timestamp": "2020-01-01 12:30:00"}
+	for name, value := range want {
+		if got := conversion.Get(name); got != value {
+			t.Errorf("Conversion %s = %q, want %q", name, got, value)
+		}
+	}
+
+	conversionStatus = http.StatusUnauthorized
+	msg = &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	_, _, err = nexmo.SMS.TrackedSend(context.Background(), msg, time.Minute)
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) {
+		t.Errorf("TrackedSend with a failed conversion report = %v, want it to wrap a *RequestError", err)
+	}
+}
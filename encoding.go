@@ -0,0 +1,291 @@
+package nexmo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Character encodings accepted by SetRawText.
+const (
+	EncodingUTF8   = "UTF-8"
+	EncodingLatin1 = "ISO-8859-1"
+	EncodingUCS2   = "UCS-2" // Big-endian, as used on the SMS air interface.
+)
+
+// SetRawText sets the message text from text already encoded in encoding,
+// one of EncodingUTF8, EncodingLatin1 or EncodingUCS2. Nexmo only accepts
+// UTF-8, so the bytes are transcoded losslessly; input that isn't valid in
+// the declared encoding is rejected rather than silently replaced. If Type is
+// unset, it becomes Unicode for UCS-2 input and Text otherwise.
+func (msg *SMSMessage) SetRawText(text []byte, encoding string) error {
+	var decoded string
+	switch encoding {
+	case EncodingUTF8:
+		if !utf8.Valid(text) {
+			return errors.New("Text is not valid UTF-8")
+		}
+		decoded = string(text)
+	case EncodingLatin1:
+		// ISO-8859-1 bytes are the first 256 Unicode code points.
+		runes := make([]rune, len(text))
+		for i, b := range text {
+			runes[i] = rune(b)
+		}
+		decoded = string(runes)
+	case EncodingUCS2:
+		if len(text)%2 != 0 {
+			return errors.New("UCS-2 text must have an even number of bytes")
+		}
+		units := make([]uint16, len(text)/2)
+		for i := range units {
+			units[i] = uint16(text[2*i])<<8 | uint16(text[2*i+1])
+		}
+		decoded = string(utf16.Decode(units))
+	default:
+		return fmt.Errorf("Unsupported text encoding %q", encoding)
+	}
+
+	msg.Text = decoded
+	if msg.Type == "" {
+		if encoding == EncodingUCS2 {
+			msg.Type = Unicode
+		} else {
+			msg.Type = Text
+		}
+	}
+	return nil
+}
+
+// gsm7Basic holds the characters of the GSM 03.38 default alphabet, each of
+// which takes one septet.
+var gsm7Basic = map[rune]bool{}
+
+// gsm7Extension holds the characters of the GSM 03.38 extension table, each of
+// which takes two septets: an escape followed by the character.
+var gsm7Extension = map[rune]bool{}
+
+func init() {
+	for _, r := range "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà" {
+		gsm7Basic[r] = true
+	}
+	for _, r := range "\f^{}\\[~]|€" {
+		gsm7Extension[r] = true
+	}
+}
+
+// Segment sizes of single and concatenated messages. Concatenated segments
+// lose room to the concatenation header.
+const (
+	gsm7SingleSeptets = 160
+	gsm7ConcatSeptets = 153
+	ucs2SingleUnits   = 70
+	ucs2ConcatUnits   = 67
+)
+
+// gsm7Alphabet is a GSM-7 character set: the characters taking one septet,
+// those taking two (an escape followed by the character), and how many
+// septets fit in a single and in a concatenated segment.
+type gsm7Alphabet struct {
+	basic, extension       map[rune]bool
+	singleSize, concatSize int
+}
+
+// defaultAlphabet is the GSM 03.38 default alphabet and extension table.
+var defaultAlphabet = &gsm7Alphabet{gsm7Basic, gsm7Extension, gsm7SingleSeptets, gsm7ConcatSeptets}
+
+// septets returns the number of septets needed for s, and whether s can be
+// encoded in a at all.
+func (a *gsm7Alphabet) septets(s string) (int, bool) {
+	septets := 0
+	for _, r := range s {
+		switch {
+		case a.basic[r]:
+			septets++
+		case a.extension[r]:
+			septets += 2
+		default:
+			return 0, false
+		}
+	}
+	return septets, true
+}
+
+// segments returns the number of segments s takes when sent in a. An escaped
+// character is never split across two segments.
+func (a *gsm7Alphabet) segments(s string) int {
+	if septets, _ := a.septets(s); septets <= a.singleSize {
+		return 1
+	}
+	segments, used := 1, 0
+	for _, r := range s {
+		size := 1
+		if !a.basic[r] {
+			size = 2
+		}
+		if used+size > a.concatSize {
+			segments++
+			used = 0
+		}
+		used += size
+	}
+	return segments
+}
+
+// septetCount returns the number of GSM-7 septets needed for s, and whether s
+// can be encoded in GSM-7 at all.
+func septetCount(s string) (int, bool) {
+	return defaultAlphabet.septets(s)
+}
+
+// SeptetCount returns the number of GSM-7 septets needed to send s. The
+// characters of the GSM 03.38 extension table, such as € and [, count as two
+// septets each. It returns -1 if s has characters GSM-7 can not encode, in
+// which case the message is sent as UCS-2.
+func SeptetCount(s string) int {
+	septets, ok := septetCount(s)
+	if !ok {
+		return -1
+	}
+	return septets
+}
+
+// UCS2SegmentCount returns the number of segments s takes when sent as UCS-2,
+// as Nexmo bills it, along with the number of UTF-16 code units per segment
+// that applied: 70 if s fits in a single message, or 67 if it is
+// concatenated, as each part loses room to the concatenation header.
+// Characters outside the Basic Multilingual Plane, such as most emoji, take
+// two code units and are never split across two segments, so a part may
+// hold 66.
+func UCS2SegmentCount(s string) (segments, unitsPerSegment int) {
+	units := 0
+	for _, r := range s {
+		units += utf16.RuneLen(r)
+	}
+	if units <= ucs2SingleUnits {
+		return 1, ucs2SingleUnits
+	}
+	segments, used := 1, 0
+	for _, r := range s {
+		size := utf16.RuneLen(r)
+		if used+size > ucs2ConcatUnits {
+			segments++
+			used = 0
+		}
+		used += size
+	}
+	return segments, ucs2ConcatUnits
+}
+
+// usesUCS2 reports whether the message text will be sent as UCS-2, either
+// because its Type is Unicode or because it can't be encoded in the default
+// GSM-7 alphabet. Language is ignored, as Nexmo can't send the national
+// language tables.
+func (msg *SMSMessage) usesUCS2() bool {
+	if msg.Type == Unicode {
+		return true
+	}
+	_, ok := defaultAlphabet.septets(msg.Text)
+	return !ok
+}
+
+// EncodingGSM7 is the GSM 03.38 7-bit alphabet, reported in
+// MessageResponse.UsedEncoding alongside EncodingUCS2.
+const EncodingGSM7 = "GSM-7"
+
+// usedEncoding returns the encoding a text or Unicode message is sent in,
+// or "" for other message types.
+func (msg *SMSMessage) usedEncoding() string {
+	switch {
+	case msg.Type != "" && msg.Type != Text && msg.Type != Unicode:
+		return ""
+	case msg.usesUCS2():
+		return EncodingUCS2
+	default:
+		return EncodingGSM7
+	}
+}
+
+// SegmentCount returns the number of SMS segments the message will be sent
+// as, computed locally from its text. Text is counted in the default GSM-7
+// alphabet if it fits, and otherwise with the national language tables of
+// Language, if set and the text fits them.
+// A message with no Type is counted as text, as that is how it is sent.
+// Binary, WAP Push, vCard and vCal messages are counted as a single segment.
+func (msg *SMSMessage) SegmentCount() int {
+	if msg.Type != "" && msg.Type != Text && msg.Type != Unicode {
+		return 1
+	}
+	if msg.Type != Unicode {
+		if _, ok := defaultAlphabet.septets(msg.Text); ok {
+			return defaultAlphabet.segments(msg.Text)
+		}
+		if _, ok := msg.alphabet().septets(msg.Text); ok {
+			return msg.alphabet().segments(msg.Text)
+		}
+	}
+	segments, _ := UCS2SegmentCount(msg.Text)
+	return segments
+}
+
+// WillSplit reports whether the message will be sent as more than one
+// segment.
+func (msg *SMSMessage) WillSplit() bool {
+	return msg.SegmentCount() > 1
+}
+
+// UnsupportedCharacter is a character of a message text that can't be sent
+// in either GSM-7 or UCS-2, and that Nexmo strips from the message.
+type UnsupportedCharacter struct {
+	Rune   rune // utf8.RuneError for bytes that aren't valid UTF-8.
+	Offset int  // Byte offset in the text.
+}
+
+// UnsupportedCharacters returns the characters of s that would be dropped
+// when sent: control characters other than line feed, carriage return and
+// form feed, Unicode noncharacters, and bytes that aren't valid UTF-8.
+func UnsupportedCharacters(s string) []UnsupportedCharacter {
+	var unsupported []UnsupportedCharacter
+	for offset, r := range s {
+		if isUnsupported(r, s[offset:]) {
+			unsupported = append(unsupported, UnsupportedCharacter{r, offset})
+		}
+	}
+	return unsupported
+}
+
+func isUnsupported(r rune, rest string) bool {
+	switch {
+	case r == utf8.RuneError:
+		// A literal U+FFFD is fine, invalid UTF-8 isn't.
+		_, size := utf8.DecodeRuneInString(rest)
+		return size == 1
+	case r == '\n' || r == '\r' || r == '\f':
+		return false
+	case unicode.IsControl(r):
+		return true
+	case r >= 0xFDD0 && r <= 0xFDEF, r&0xFFFE == 0xFFFE:
+		// Noncharacters.
+		return true
+	}
+	return false
+}
+
+// UnsupportedCharactersError is returned by Send when
+// SMS.RejectUnsupportedCharacters is set and the text has characters that
+// would be dropped.
+type UnsupportedCharactersError struct {
+	Characters []UnsupportedCharacter
+}
+
+func (e *UnsupportedCharactersError) Error() string {
+	chars := make([]string, len(e.Characters))
+	for i, c := range e.Characters {
+		chars[i] = fmt.Sprintf("%U at %d", c.Rune, c.Offset)
+	}
+	return "Message text has unsupported characters: " + strings.Join(chars, ", ")
+}
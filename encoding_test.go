@@ -0,0 +1,241 @@
+package nexmo
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var setRawTextTests = []struct {
+	text     []byte
+	encoding string
+	want     string
+	wantType string
+	wantErr  bool
+}{
+	{[]byte("hello"), EncodingUTF8, "hello", Text, false},
+	{[]byte{0xff, 0xfe}, EncodingUTF8, "", "", true},
+	{[]byte{'c', 'a', 'f', 0xe9}, EncodingLatin1, "café", Text, false},
+	{[]byte{0x00, 'h', 0x00, 'i'}, EncodingUCS2, "hi", Unicode, false},
+	{[]byte{0x04, 0x1f}, EncodingUCS2, "П", Unicode, false},
+	{[]byte{0x00}, EncodingUCS2, "", "", true},
+	{[]byte("hello"), "EBCDIC", "", "", true},
+}
+
+func TestSetRawText(t *testing.T) {
+	for _, test := range setRawTextTests {
+		msg := &SMSMessage{}
+		err := msg.SetRawText(test.text, test.encoding)
+		if (err != nil) != test.wantErr {
+			t.Errorf("SetRawText(%v, %s) error = %v, want error: %v",
+				test.text, test.encoding, err, test.wantErr)
+			continue
+		}
+		if msg.Text != test.want || msg.Type != test.wantType {
+			t.Errorf("SetRawText(%v, %s) = %q (%s), want %q (%s)",
+				test.text, test.encoding, msg.Text, msg.Type, test.want, test.wantType)
+		}
+	}
+}
+
+var segmentCountTests = []struct {
+	msgType string
+	text    string
+	want    int
+}{
+	{Text, "", 1},
+	{Text, "Hello", 1},
+	{Text, strings.Repeat("a", 160), 1},
+	{Text, strings.Repeat("a", 161), 2},
+	{Text, strings.Repeat("a", 306), 2},
+	{Text, strings.Repeat("a", 307), 3},
+	{Text, strings.Repeat("€", 80), 1},
+	{Text, strings.Repeat("€", 81), 2},
+	{Text, strings.Repeat("a", 152) + "€", 1},
+	{Text, strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152), 3},
+	{Text, strings.Repeat("a", 70) + "П", 2},
+	{Unicode, "Hello", 1},
+	{Unicode, strings.Repeat("П", 70), 1},
+	{Unicode, strings.Repeat("П", 71), 2},
+	{Unicode, strings.Repeat("П", 134), 2},
+	{Unicode, strings.Repeat("П", 135), 3},
+	{Unicode, strings.Repeat("😀", 35), 1},
+	{Unicode, strings.Repeat("😀", 36), 2},
+	{Unicode, strings.Repeat("П", 66) + "😀", 1},
+	{Unicode, strings.Repeat("П", 66) + "😀" + strings.Repeat("П", 66), 3},
+	{Binary, strings.Repeat("a", 500), 1},
+	{"", "Hello", 1},
+	{"", strings.Repeat("a", 1000), 7},
+	{"", strings.Repeat("П", 71), 2},
+}
+
+func TestSendMaxSegmentsNoType(t *testing.T) {
+	var calls int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	nexmo.SMS.MaxSegments = 1
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Text: strings.Repeat("a", 1000)}
+	if _, err := nexmo.SMS.Send(msg); err == nil || calls != 0 {
+		t.Errorf("Send of a long message with no Type = %v after %d calls, want a local error", err, calls)
+	}
+}
+
+func TestSegmentCount(t *testing.T) {
+	for _, test := range segmentCountTests {
+		msg := &SMSMessage{Type: test.msgType, Text: test.text}
+		got := msg.SegmentCount()
+		if got != test.want {
+			t.Errorf("SegmentCount() of %s %q = %d, want %d",
+				test.msgType, test.text, got, test.want)
+		}
+		if msg.WillSplit() != (test.want > 1) {
+			t.Errorf("WillSplit() of %s %q = %v, want %v",
+				test.msgType, test.text, msg.WillSplit(), test.want > 1)
+		}
+	}
+}
+
+// ucs2SegmentCountTests follow Nexmo's billing: 70 code units in a single
+// message, 67 in each part of a concatenated one.
+var ucs2SegmentCountTests = []struct {
+	text         string
+	wantSegments int
+	wantUnits    int
+}{
+	{"", 1, 70},
+	{strings.Repeat("П", 70), 1, 70},
+	{strings.Repeat("П", 71), 2, 67},
+	{strings.Repeat("П", 134), 2, 67},
+	{strings.Repeat("П", 135), 3, 67},
+	{strings.Repeat("П", 201), 3, 67},
+	{strings.Repeat("П", 202), 4, 67},
+	{strings.Repeat("😀", 35), 1, 70},
+	{strings.Repeat("😀", 36), 2, 67},
+	{strings.Repeat("П", 66) + "😀", 1, 70},
+	{strings.Repeat("П", 68) + "😀", 1, 70},
+	{strings.Repeat("П", 69) + "😀", 2, 67},
+}
+
+func TestUCS2SegmentCount(t *testing.T) {
+	for _, test := range ucs2SegmentCountTests {
+		segments, units := UCS2SegmentCount(test.text)
+		if segments != test.wantSegments || units != test.wantUnits {
+			t.Errorf("UCS2SegmentCount(%q) = %d, %d, want %d, %d",
+				test.text, segments, units, test.wantSegments, test.wantUnits)
+		}
+	}
+}
+
+// septetCountTests are taken from the GSM 03.38 default alphabet and its
+// extension table.
+var septetCountTests = []struct {
+	text string
+	want int
+}{
+	{"", 0},
+	{"Hello, world!", 13},
+	{"@£$¥èéùìòÇ\nØø\rÅå", 16},
+	{"Δ_ΦΓΛΩΠΨΣΘΞ", 11},
+	{"ÆæßÉ¤¡ÄÖÑÜ§¿äöñüà", 17},
+	{"\f", 2},
+	{"^{}\\[~]|€", 18},
+	{"Price: 5€", 10},
+	{strings.Repeat("€", 80), 160},
+	{"ç", -1}, // Only the upper case Ç is in the default alphabet.
+	{"`", -1},
+	{"П", -1},
+	{"😀", -1},
+}
+
+func TestSeptetCount(t *testing.T) {
+	for _, test := range septetCountTests {
+		if got := SeptetCount(test.text); got != test.want {
+			t.Errorf("SeptetCount(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
+
+var autoUnicodeTests = []struct {
+	msgType      string
+	text         string
+	autoUnicode  bool
+	wantType     string
+	wantEncoding string
+}{
+	{Text, "Hello", true, "text", EncodingGSM7},
+	{Text, "Привет", true, "unicode", EncodingUCS2},
+	{"", "Привет", true, "unicode", EncodingUCS2},
+	{Text, "Привет", false, "text", EncodingUCS2},
+	{Unicode, "Hello", true, "unicode", EncodingUCS2},
+}
+
+func TestAutoUnicode(t *testing.T) {
+	var sentType string
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sentType = r.PostForm.Get("type")
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+
+	for _, test := range autoUnicodeTests {
+		nexmo.SMS.AutoUnicode = test.autoUnicode
+		msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: test.msgType, Text: test.text}
+		resp, err := nexmo.SMS.Send(msg)
+		if err != nil {
+			t.Errorf("Send(%s %q) failed with error: %v", test.msgType, test.text, err)
+			continue
+		}
+		if sentType != test.wantType || resp.UsedEncoding != test.wantEncoding {
+			t.Errorf("Send(%s %q) with AutoUnicode %v sent type %q in %s, want %q in %s",
+				test.msgType, test.text, test.autoUnicode, sentType, resp.UsedEncoding,
+				test.wantType, test.wantEncoding)
+		}
+	}
+}
+
+var unsupportedCharactersTests = []struct {
+	text string
+	want []UnsupportedCharacter
+}{
+	{"Hello\r\nworld", nil},
+	{"Привет 😀 €", nil},
+	{"\ufffd", nil},
+	{"a\x00b", []UnsupportedCharacter{{0, 1}}},
+	{"tab\there\x1b", []UnsupportedCharacter{{'\t', 3}, {0x1b, 8}}},
+	{"a\u0085b", []UnsupportedCharacter{{0x85, 1}}},
+	{"x\uffffy\ufdd0", []UnsupportedCharacter{{0xFFFF, 1}, {0xFDD0, 5}}},
+	{"ok\xffok", []UnsupportedCharacter{{utf8.RuneError, 2}}},
+}
+
+func TestUnsupportedCharacters(t *testing.T) {
+	for _, test := range unsupportedCharactersTests {
+		got := UnsupportedCharacters(test.text)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("UnsupportedCharacters(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestRejectUnsupportedCharacters(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.RejectUnsupportedCharacters = true
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello\x07"}
+	_, err := nexmo.SMS.Send(msg)
+	var unsupported *UnsupportedCharactersError
+	if !errors.As(err, &unsupported) || len(unsupported.Characters) != 1 {
+		t.Errorf("Send of text with a control character = %v, want an UnsupportedCharactersError", err)
+	}
+
+	msg = &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if _, err := nexmo.SMS.Send(msg); err != nil {
+		t.Error("Send failed with error:", err)
+	}
+}
@@ -0,0 +1,162 @@
+package nexmo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestEvent describes a request about to be sent to Nexmo, for
+// Client.OnRequest.
+type RequestEvent struct {
+	Method string
+	URL    string // With the API secret redacted.
+
+	// Params are the query and form parameters of the request, with the
+	// API secret and signature redacted.
+	Params url.Values
+
+	// TraceID is the trace ID set with WithLogger, if any.
+	TraceID string
+
+	// CorrelationID is the MessageResponse.CorrelationID of the send the
+	// request is for, if it sends an SMS.
+	CorrelationID string
+
+	// Metadata is the SMSMessage.Metadata of the send the request is for,
+	// if any. It is never sent to Nexmo.
+	Metadata map[string]string
+
+	// Attempt counts the attempts at the request, starting at 1, when
+	// Client.Backoff retries it.
+	Attempt int
+}
+
+// ResponseEvent describes the outcome of a request to Nexmo, for
+// Client.OnResponse.
+type ResponseEvent struct {
+	RequestEvent
+
+	// StatusCode is the HTTP status of the response, or 0 if there was none.
+	StatusCode int
+	Latency    time.Duration
+
+	// NexmoStatus is the status or error code Nexmo reported in the
+	// response body, if it has one: the first unsuccessful status of the
+	// messages of an SMS, or the status or error-code of other APIs.
+	NexmoStatus string
+
+	// Err is the error sending the request, if any. A response with an
+	// error status is not an error here.
+	Err error
+}
+
+type correlationIDContextKey struct{}
+
+// withCorrelationID returns a copy of ctx whose requests are reported to the
+// event hooks with correlationID.
+func withCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, correlationID)
+}
+
+type metadataContextKey struct{}
+
+// withMetadata returns a copy of ctx whose requests are reported to the event
+// hooks with metadata.
+func withMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	return context.WithValue(ctx, metadataContextKey{}, metadata)
+}
+
+// redactedParams are replaced in event parameters.
+var redactedParams = []string{"api_secret", "sig"}
+
+// redactParams returns a copy of params with the API secret and signature
+// redacted, safe to log.
+func redactParams(params url.Values) url.Values {
+	redacted := make(url.Values, len(params))
+	for name, values := range params {
+		redacted[name] = append([]string(nil), values...)
+	}
+	for _, name := range redactedParams {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "REDACTED")
+		}
+	}
+	return redacted
+}
+
+// requestEvent describes r for the event hooks. The form body, if any, is
+// read through GetBody so r itself is left untouched.
+func (c *Client) requestEvent(r *http.Request, attempt int) RequestEvent {
+	params := r.URL.Query()
+	if r.GetBody != nil && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if body, err := r.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(body)
+			body.Close()
+			if form, err := url.ParseQuery(string(data)); err == nil {
+				for name, values := range form {
+					params[name] = append(params[name], values...)
+				}
+			}
+		}
+	}
+	event := RequestEvent{
+		Method:  r.Method,
+		URL:     c.redactURL(r.URL),
+		Params:  redactParams(params),
+		Attempt: attempt,
+	}
+	if cl, ok := r.Context().Value(loggerContextKey{}).(contextLogger); ok {
+		event.TraceID = cl.traceID
+	}
+	event.CorrelationID, _ = r.Context().Value(correlationIDContextKey{}).(string)
+	event.Metadata, _ = r.Context().Value(metadataContextKey{}).(map[string]string)
+	return event
+}
+
+// nexmoStatus returns the status or error code in the body of resp, if any.
+// The body is read and replaced, so the caller can still read it in full.
+func nexmoStatus(resp *http.Response) string {
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+
+	var body struct {
+		Status    json.RawMessage `json:"status"`
+		ErrorCode json.RawMessage `json:"error-code"`
+		Messages  []struct {
+			Status json.RawMessage `json:"status"`
+		} `json:"messages"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return ""
+	}
+	status := ""
+	for _, message := range body.Messages {
+		status = rawString(message.Status)
+		if status != strconv.Itoa(int(ResponseSuccess)) {
+			break
+		}
+	}
+	if status == "" {
+		status = rawString(body.Status)
+	}
+	if status == "" {
+		status = rawString(body.ErrorCode)
+	}
+	return status
+}
+
+// rawString returns the JSON string or number raw as a plain string.
+func rawString(raw json.RawMessage) string {
+	return strings.Trim(string(raw), `"`)
+}
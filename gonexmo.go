@@ -10,5 +10,6 @@ package nexmo
 
 const (
 	apiRoot    = "https://rest.nexmo.com"
+	apiRootv2  = "https://api.nexmo.com"
 	TimeFormat = "2006-01-02 15:04:05"
 )
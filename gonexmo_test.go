@@ -2,6 +2,8 @@ package nexmo
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -40,3 +42,21 @@ func TestNexmoCreation(t *testing.T) {
 		t.Error("Failed to create Client with error:", err)
 	}
 }
+
+// newTestClient returns a Client that sends every request to handler and
+// uses a fake clock, so tests run without the network or real waiting. The
+// server is closed when the test ends.
+func newTestClient(t *testing.T, handler http.Handler) (*Client, *fakeClock) {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	if err != nil {
+		t.Fatal("Failed to create Client with error:", err)
+	}
+	clk := newFakeClock()
+	nexmo.clock = clk
+	nexmo.restURL = ts.URL
+	nexmo.apiURL = ts.URL
+	return nexmo, clk
+}
@@ -0,0 +1,119 @@
+package nexmo
+
+import (
+	"sync"
+	"time"
+)
+
+// IdempotencyStore remembers the responses to sends made with an
+// SMSMessage.IdempotencyKey, so a repeated send with the same key returns the
+// original response instead of sending the message again.
+//
+// A store shared between processes, e.g. one backed by Redis, lets the
+// guard work across instances behind a load balancer. Get and Put may be
+// called concurrently. Sends with the same key from one Client wait for each
+// other, so overlapping duplicates are sent once; across processes, two that
+// overlap in time can both be sent, since neither has been Put when the other
+// calls Get.
+type IdempotencyStore interface {
+	// Get returns the response stored for key, if there is one.
+	Get(key string) (*MessageResponse, bool)
+
+	// Put stores resp as the response to the send made with key.
+	Put(key string, resp *MessageResponse)
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore holding responses in memory
+// for a fixed time. It only deduplicates sends made by the same process.
+type MemoryIdempotencyStore struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	resp    *MessageResponse
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates a MemoryIdempotencyStore that remembers
+// each response for ttl.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return newMemoryIdempotencyStore(ttl, time.Now)
+}
+
+// newMemoryIdempotencyStore creates a MemoryIdempotencyStore that tells the
+// time with now, such as the clock of a Client.
+func newMemoryIdempotencyStore(ttl time.Duration, now func() time.Time) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		now:     now,
+		entries: make(map[string]idempotencyEntry),
+	}
+}
+
+// Get returns the response stored for key, if it hasn't expired.
+func (s *MemoryIdempotencyStore) Get(key string) (*MessageResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || s.now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// Put stores resp for key, and drops any expired responses.
+func (s *MemoryIdempotencyStore) Put(key string, resp *MessageResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = idempotencyEntry{resp, now.Add(s.ttl)}
+}
+
+// keyLocks serializes the sends sharing an idempotency key, so a duplicate
+// sent while the first is in flight waits for its response instead of also
+// missing the store and sending the message twice.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks key and returns the function unlocking it.
+func (k *keyLocks) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,43 @@
+package nexmo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Hour)
+	resp := &MessageResponse{MessageCount: 1}
+
+	if _, ok := store.Get("key"); ok {
+		t.Error("Empty store returned a response")
+	}
+
+	store.Put("key", resp)
+	if got, ok := store.Get("key"); !ok || got != resp {
+		t.Error("Stored response was not returned")
+	}
+
+	expired := NewMemoryIdempotencyStore(-time.Second)
+	expired.Put("key", resp)
+	if _, ok := expired.Get("key"); ok {
+		t.Error("Expired response was returned")
+	}
+}
+
+func TestClientIdempotencyStoreClock(t *testing.T) {
+	nexmo, clk := newTestClient(t, http.NotFoundHandler())
+	store := nexmo.SMS.IdempotencyStore
+	resp := &MessageResponse{MessageCount: 1}
+
+	store.Put("key", resp)
+	clk.Advance(23 * time.Hour)
+	if _, ok := store.Get("key"); !ok {
+		t.Error("Response was not returned within a day on the Client's clock")
+	}
+	clk.Advance(2 * time.Hour)
+	if _, ok := store.Get("key"); ok {
+		t.Error("Response was returned after a day on the Client's clock")
+	}
+}
@@ -0,0 +1,94 @@
+package nexmo
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// InboundOptions filters and pages the results of Numbers.InboundMessages.
+type InboundOptions struct {
+	// Start and End bound the time the messages were received. Start is
+	// required by the Reports API; End defaults to now.
+	Start time.Time
+	End   time.Time
+
+	// PageSize is the number of messages per page, up to 1000.
+	PageSize int
+
+	// Cursor is the Next cursor of the previous page. Leave it empty to get
+	// the first page.
+	Cursor string
+}
+
+// InboundPage is one page of inbound messages.
+type InboundPage struct {
+	Messages []*RecvdMessage
+
+	// Next is the cursor of the next page, or "" if this is the last page.
+	Next string
+}
+
+/*
+	GET https://api.nexmo.com/v2/reports/records?account_id={api_key}&product=SMS&direction=inbound&to={msisdn}&date_start={start}&date_end={end}&include_message=true
+*/
+
+// InboundMessages returns the messages the account received on msisdn, e.g.
+// to backfill messages whose webhooks were missed. Message bodies are only
+// kept by Nexmo for a limited time, so old messages may have an empty Text.
+func (c *Numbers) InboundMessages(msisdn string, opts InboundOptions) (*InboundPage, error) {
+	if len(msisdn) <= 0 {
+		return nil, errors.New("Invalid number field specified")
+	}
+	if opts.Start.IsZero() {
+		return nil, errors.New("Invalid start time specified")
+	}
+
+	values := url.Values{}
+	values.Set("direction", "inbound")
+	values.Set("to", msisdn)
+	values.Set("include_message", "true")
+	values.Set("date_start", opts.Start.UTC().Format(time.RFC3339))
+	if !opts.End.IsZero() {
+		values.Set("date_end", opts.End.UTC().Format(time.RFC3339))
+	}
+	if opts.PageSize > 0 {
+		values.Set("page_size", strconv.Itoa(opts.PageSize))
+	}
+	if opts.Cursor != "" {
+		values.Set("cursor", opts.Cursor)
+	}
+
+	response, err := c.client.Reports.records(values)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &InboundPage{
+		Messages: make([]*RecvdMessage, 0, len(response.Records)),
+		Next:     response.nextCursor(),
+	}
+	for _, record := range response.Records {
+		page.Messages = append(page.Messages, record.inboundMessage())
+	}
+	return page, nil
+}
+
+// inboundMessage converts the record of an inbound SMS to the type the
+// inbound message handler produces.
+func (r *DeliveryStatusRecord) inboundMessage() *RecvdMessage {
+	m := &RecvdMessage{
+		Type:        TextMessage,
+		To:          r.To,
+		MSISDN:      r.From,
+		NetworkCode: r.Network,
+		ID:          r.MessageID,
+		Text:        r.MessageBody,
+		Timestamp:   r.DateReceived,
+	}
+	if _, ok := septetCount(r.MessageBody); !ok {
+		m.Type = UnicodeMessage
+	}
+	return m
+}
@@ -0,0 +1,65 @@
+package nexmo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInboundMessages(t *testing.T) {
+	var query map[string][]string
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{
+			"request_status": "SUCCESS",
+			"_links": {"next": {"href": "https://api.nexmo.com/v2/reports/records?cursor=abc123&page_size=2"}},
+			"records": [
+				{"message_id": "0A00000001", "direction": "inbound", "from": "447700900001", "to": "447700900000",
+				 "network": "23410", "date_received": "2020-01-01T12:00:00Z", "message_body": "Hello"},
+				{"message_id": "0A00000002", "direction": "inbound", "from": "447700900002", "to": "447700900000",
+				 "network": "23415", "date_received": "2020-01-01T12:05:30Z", "message_body": "Привет"}
+			]
+		}`))
+	}))
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	page, err := nexmo.Numbers.InboundMessages("447700900000", InboundOptions{Start: start, PageSize: 2})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	want := map[string]string{
+		"direction":  "inbound",
+		"to":         "447700900000",
+		"date_start": "2020-01-01T00:00:00Z",
+		"page_size":  "2",
+		"product":    "SMS",
+	}
+	for k, v := range want {
+		if got := query[k]; len(got) != 1 || got[0] != v {
+			t.Errorf("Query parameter %s = %v, want %s", k, got, v)
+		}
+	}
+
+	if page.Next != "abc123" {
+		t.Errorf("Next = %q, want abc123", page.Next)
+	}
+	if len(page.Messages) != 2 {
+		t.Fatalf("Got %d messages, want 2", len(page.Messages))
+	}
+	m := page.Messages[0]
+	if m.ID != "0A00000001" || m.MSISDN != "447700900001" || m.To != "447700900000" ||
+		m.NetworkCode != "23410" || m.Text != "Hello" || m.Type != TextMessage {
+		t.Errorf("Unexpected first message: %+v", m)
+	}
+	if !m.Timestamp.Equal(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v", m.Timestamp)
+	}
+	if page.Messages[1].Type != UnicodeMessage {
+		t.Errorf("Type of a Cyrillic message = %v, want unicode", page.Messages[1].Type)
+	}
+
+	if _, err := nexmo.Numbers.InboundMessages("447700900000", InboundOptions{}); err == nil {
+		t.Error("Expected an error without a start time")
+	}
+}
@@ -0,0 +1,255 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Insight represents the Number Insight API functions for looking up
+// information about a phone number.
+type Insight struct {
+	client *Client
+
+	// BatchConcurrency is the number of lookups BatchStandard runs at once.
+	// Values below one mean one.
+	BatchConcurrency int
+
+	// RateLimit, if greater than zero, is the maximum number of lookups
+	// sent per second, across all goroutines.
+	RateLimit float64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until another lookup may be sent under RateLimit.
+func (c *Insight) wait() {
+	if c.RateLimit <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.client.clock.Now()
+	if now.Before(c.next) {
+		<-c.client.clock.After(c.next.Sub(now))
+		now = c.next
+	}
+	c.next = now.Add(time.Duration(float64(time.Second) / c.RateLimit))
+}
+
+// Network types reported by Number Insight for a carrier.
+const (
+	NetworkMobile           = "mobile"
+	NetworkLandline         = "landline"
+	NetworkLandlinePremium  = "landline_premium"
+	NetworkLandlineTollFree = "landline_tollfree"
+	NetworkVirtual          = "virtual"
+	NetworkPager            = "pager"
+	NetworkUnknown          = "unknown"
+
+	// NetworkShortCode is not reported by Number Insight, which can't look
+	// up short codes. NumberType returns it for numbers of 3 to 6 digits.
+	NetworkShortCode = "short_code"
+)
+
+// Carrier describes the network a number belongs to.
+type Carrier struct {
+	NetworkCode string `json:"network_code"`
+	Name        string `json:"name"`
+	Country     string `json:"country"`
+	NetworkType string `json:"network_type"`
+}
+
+// InsightStandardResponse is the result of a Number Insight Standard lookup.
+type InsightStandardResponse struct {
+	Status                    int     `json:"status"`
+	StatusMessage             string  `json:"status_message"`
+	RequestID                 string  `json:"request_id"`
+	InternationalFormatNumber string  `json:"international_format_number"`
+	NationalFormatNumber      string  `json:"national_format_number"`
+	CountryCode               string  `json:"country_code"`
+	CountryCodeISO3           string  `json:"country_code_iso3"`
+	CountryName               string  `json:"country_name"`
+	CountryPrefix             string  `json:"country_prefix"`
+	RequestPrice              string  `json:"request_price"`
+	RemainingBalance          string  `json:"remaining_balance"`
+	CurrentCarrier            Carrier `json:"current_carrier"`
+	OriginalCarrier           Carrier `json:"original_carrier"`
+	Ported                    string  `json:"ported"`
+}
+
+/*
+	GET https://api.nexmo.com/ni/standard/json?api_key={api_key}&api_secret={api_secret}&number={number}
+*/
+
+// Standard performs a Number Insight Standard lookup on the given number,
+// returning its current and original carrier.
+func (c *Insight) Standard(number string) (insightResponse *InsightStandardResponse, err error) {
+	if len(number) <= 0 {
+		return nil, errors.New("Invalid number field specified")
+	}
+
+	values := url.Values{}
+	values.Set("api_key", c.client.apiKey)
+	values.Set("api_secret", c.client.apiSecret)
+	values.Set("number", number)
+
+	r, _ := http.NewRequest("GET", c.client.apiURL+"/ni/standard/json?"+values.Encode(), nil)
+
+	c.wait()
+	defer c.client.wrapError(r, &err)
+	resp, err := c.client.do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	err = json.Unmarshal(body, &insightResponse)
+	if err != nil {
+		return nil, err
+	}
+	if insightResponse.Status != 0 {
+		return nil, fmt.Errorf("Number Insight lookup failed (status %d): %s",
+			insightResponse.Status, insightResponse.StatusMessage)
+	}
+	return insightResponse, nil
+}
+
+// isShortCode reports whether number is short enough to be a short code,
+// rather than an international number.
+func isShortCode(number string) bool {
+	if len(number) < 3 || len(number) > 6 {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NumberType classifies number by the type of network it belongs to, one of
+// the Network constants, e.g. to refuse premium rate numbers. Short codes are
+// recognised locally; other numbers are looked up with Number Insight
+// Standard.
+func (c *Insight) NumberType(number string) (string, error) {
+	if isShortCode(number) {
+		return NetworkShortCode, nil
+	}
+	insight, err := c.Standard(number)
+	if err != nil {
+		return "", err
+	}
+	if insight.CurrentCarrier.NetworkType == "" {
+		return NetworkUnknown, nil
+	}
+	return insight.CurrentCarrier.NetworkType, nil
+}
+
+// InsightResult is the outcome of looking up a single number in a batch.
+type InsightResult struct {
+	Number   string
+	Response *InsightStandardResponse // Nil if Err is set.
+	Err      error
+}
+
+// BatchStandard performs a Number Insight Standard lookup on every number,
+// running up to BatchConcurrency lookups at once within RateLimit, and
+// returns their results in the same order as numbers. A failed lookup
+// doesn't stop the others; the returned error, if any, only says how many
+// failed, and each failure is in its result's Err.
+func (c *Insight) BatchStandard(numbers []string) ([]InsightResult, error) {
+	results := make([]InsightResult, len(numbers))
+	runBatch(len(numbers), c.BatchConcurrency, func(i int) {
+		resp, err := c.Standard(numbers[i])
+		results[i] = InsightResult{Number: numbers[i], Response: resp, Err: err}
+	})
+	return results, batchError(len(results), "Number Insight lookups", func(i int) bool {
+		return results[i].Err != nil
+	})
+}
+
+// NetworkCode returns the MCCMNC of the network number currently belongs to,
+// as found by a Number Insight Standard lookup. This accounts for number
+// portability, so it may differ from the network the number was issued by.
+func (c *Insight) NetworkCode(number string) (string, error) {
+	insight, err := c.Standard(number)
+	if err != nil {
+		return "", err
+	}
+	if insight.CurrentCarrier.NetworkCode == "" {
+		return "", fmt.Errorf("No network found for %s", number)
+	}
+	return insight.CurrentCarrier.NetworkCode, nil
+}
+
+// Number Insight lookup levels.
+const (
+	InsightBasic    = "basic"
+	InsightStandard = "standard"
+	InsightAdvanced = "advanced"
+)
+
+// Outcomes of an Advanced lookup, reported in InsightCallback.LookupOutcome.
+const (
+	LookupOutcomeSuccess = 0
+	LookupOutcomePartial = 1
+	LookupOutcomeFailed  = 2
+)
+
+// InsightCallback is the result of an asynchronous Number Insight lookup, as
+// posted to the callback URL. The fields present depend on the level of the
+// lookup: Basic results only have the number and country fields, Standard
+// ones add the carriers and pricing, and Advanced ones add the lookup
+// outcome and reachability.
+type InsightCallback struct {
+	InsightStandardResponse
+
+	// Level is InsightBasic, InsightStandard or InsightAdvanced, detected
+	// from the fields present in the callback.
+	Level string `json:"-"`
+
+	LookupOutcome        int    `json:"lookup_outcome"`
+	LookupOutcomeMessage string `json:"lookup_outcome_message"`
+	ValidNumber          string `json:"valid_number"`
+	Reachable            string `json:"reachable"`
+}
+
+// Partial reports whether an Advanced lookup could only be partly completed,
+// in which case some fields may be missing.
+func (cb *InsightCallback) Partial() bool {
+	return cb.Level == InsightAdvanced && cb.LookupOutcome == LookupOutcomePartial
+}
+
+// ParseInsightCallback decodes the body of a Number Insight callback of any
+// level.
+func ParseInsightCallback(body []byte) (*InsightCallback, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	callback := &InsightCallback{}
+	if err := json.Unmarshal(body, callback); err != nil {
+		return nil, err
+	}
+
+	if _, ok := fields["lookup_outcome"]; ok {
+		callback.Level = InsightAdvanced
+	} else if _, ok := fields["current_carrier"]; ok {
+		callback.Level = InsightStandard
+	} else {
+		callback.Level = InsightBasic
+	}
+	return callback, nil
+}
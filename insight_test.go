@@ -0,0 +1,168 @@
+package nexmo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var insightCallbackTests = []struct {
+	body        string
+	wantLevel   string
+	wantPartial bool
+}{
+	{`{"status": 0, "request_id": "aaaaaaaa-bbbb-cccc-dddd-0123456789ab",
+		"international_format_number": "447700900000", "country_code": "GB"}`,
+		InsightBasic, false},
+	{`{"status": 0, "request_id": "aaaaaaaa-bbbb-cccc-dddd-0123456789ab",
+		"international_format_number": "447700900000", "country_code": "GB",
+		"request_price": "0.00500000", "remaining_balance": "10.00",
+		"current_carrier": {"network_code": "23410", "name": "O2", "country": "GB", "network_type": "mobile"},
+		"original_carrier": {"network_code": "23410", "name": "O2", "country": "GB", "network_type": "mobile"},
+		"ported": "not_ported"}`,
+		InsightStandard, false},
+	{`{"status": 0, "request_id": "aaaaaaaa-bbbb-cccc-dddd-0123456789ab",
+		"international_format_number": "447700900000", "country_code": "GB",
+		"current_carrier": {"network_code": "23410", "name": "O2", "country": "GB", "network_type": "mobile"},
+		"lookup_outcome": 0, "lookup_outcome_message": "Success",
+		"valid_number": "valid", "reachable": "reachable"}`,
+		InsightAdvanced, false},
+	{`{"status": 0, "request_id": "aaaaaaaa-bbbb-cccc-dddd-0123456789ab",
+		"international_format_number": "447700900000", "country_code": "GB",
+		"lookup_outcome": 1, "lookup_outcome_message": "Partial success - some fields populated",
+		"valid_number": "valid", "reachable": "unknown"}`,
+		InsightAdvanced, true},
+}
+
+func TestParseInsightCallback(t *testing.T) {
+	for _, test := range insightCallbackTests {
+		callback, err := ParseInsightCallback([]byte(test.body))
+		if err != nil {
+			t.Errorf("ParseInsightCallback(%s) failed with error: %v", test.body, err)
+			continue
+		}
+		if callback.Level != test.wantLevel || callback.Partial() != test.wantPartial {
+			t.Errorf("ParseInsightCallback(%s) = %s (partial %v), want %s (partial %v)",
+				test.body, callback.Level, callback.Partial(), test.wantLevel, test.wantPartial)
+		}
+		if callback.InternationalFormatNumber != "447700900000" {
+			t.Errorf("ParseInsightCallback(%s) lost the number", test.body)
+		}
+	}
+
+	if _, err := ParseInsightCallback([]byte("not json")); err == nil {
+		t.Error("Expected an error for a body that isn't JSON")
+	}
+}
+
+func TestResolveNetworkCode(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ni/standard/json" || r.URL.Query().Get("number") != "447700900000" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"status": 0, "international_format_number": "447700900000",
+			"current_carrier": {"network_code": "23415", "name": "Vodafone", "country": "GB", "network_type": "mobile"},
+			"original_carrier": {"network_code": "23410", "name": "O2", "country": "GB", "network_type": "mobile"},
+			"ported": "ported"}`))
+	}))
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if err := nexmo.SMS.ResolveNetworkCode(msg); err != nil {
+		t.Fatal("ResolveNetworkCode failed with error:", err)
+	}
+	if msg.NetworkCode != "23415" {
+		t.Errorf("NetworkCode = %q, want the current carrier 23415", msg.NetworkCode)
+	}
+
+	if err := nexmo.SMS.ResolveNetworkCode(&SMSMessage{}); err == nil {
+		t.Error("Expected an error without a To number")
+	}
+}
+
+func TestBatchStandard(t *testing.T) {
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		number := r.URL.Query().Get("number")
+		if number == "447700900002" {
+			w.Write([]byte(`{"status": 3, "status_message": "Invalid number format"}`))
+			return
+		}
+		w.Write([]byte(`{"status": 0, "international_format_number": "` + number + `",
+			"current_carrier": {"network_code": "23410", "name": "O2", "country": "GB", "network_type": "mobile"}}`))
+	}))
+	nexmo.Insight.BatchConcurrency = 2
+	nexmo.Insight.RateLimit = 10
+
+	numbers := []string{"447700900001", "447700900002", "447700900003", "447700900004"}
+	start := clk.Now()
+	results, err := nexmo.Insight.BatchStandard(numbers)
+	if err == nil {
+		t.Error("Expected an error reporting the failed lookup")
+	}
+	if len(results) != len(numbers) {
+		t.Fatalf("Got %d results, want %d", len(results), len(numbers))
+	}
+	for i, result := range results {
+		if result.Number != numbers[i] {
+			t.Errorf("Result %d is for %s, want %s", i, result.Number, numbers[i])
+		}
+		if i == 1 {
+			if result.Err == nil || result.Response != nil {
+				t.Errorf("Unexpected result for a failed lookup: %+v", result)
+			}
+			continue
+		}
+		if result.Err != nil || result.Response.InternationalFormatNumber != numbers[i] {
+			t.Errorf("Unexpected result for %s: %+v", numbers[i], result)
+		}
+	}
+	if elapsed := clk.Now().Sub(start); elapsed < 300*time.Millisecond {
+		t.Errorf("4 lookups at 10 per second took %v, want at least 300ms", elapsed)
+	}
+}
+
+func TestBlockedNetworkTypes(t *testing.T) {
+	var sent, lookups int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ni/standard/json" {
+			lookups++
+			networkType := NetworkMobile
+			if r.URL.Query().Get("number") == "447000900000" {
+				networkType = NetworkLandlinePremium
+			}
+			w.Write([]byte(`{"status": 0, "current_carrier": {"network_code": "23410", "network_type": "` + networkType + `"}}`))
+			return
+		}
+		sent++
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.BlockedNetworkTypes = []string{NetworkLandlinePremium, NetworkShortCode}
+
+	if networkType, err := nexmo.Insight.NumberType("447000900000"); err != nil || networkType != NetworkLandlinePremium {
+		t.Errorf("NumberType of a premium number = %q, %v", networkType, err)
+	}
+	if networkType, err := nexmo.Insight.NumberType("12345"); err != nil || networkType != NetworkShortCode || lookups != 1 {
+		t.Errorf("NumberType of a short code = %q, %v after %d lookups, want it found locally", networkType, err, lookups)
+	}
+
+	msg := func(to string) *SMSMessage {
+		return &SMSMessage{From: "gonexmo", To: to, Type: Text, Text: "Your code is 1234"}
+	}
+	if _, err := nexmo.SMS.SendWithCarrierCheck(msg("447000900000")); !errors.Is(err, ErrBlockedNetworkType) {
+		t.Errorf("Send to a premium number = %v, want ErrBlockedNetworkType", err)
+	}
+	if _, err := nexmo.SMS.Send(msg("12345")); !errors.Is(err, ErrBlockedNetworkType) {
+		t.Errorf("Send to a short code = %v, want ErrBlockedNetworkType", err)
+	}
+	if _, err := nexmo.SMS.SendWithCarrierCheck(msg("12345")); !errors.Is(err, ErrBlockedNetworkType) {
+		t.Errorf("Send with carrier check to a short code = %v, want ErrBlockedNetworkType", err)
+	}
+	if sent != 0 {
+		t.Errorf("Sent %d messages to blocked numbers", sent)
+	}
+
+	if _, err := nexmo.SMS.SendWithCarrierCheck(msg("447700900000")); err != nil || sent != 1 {
+		t.Errorf("Send to a mobile number = %v, want it sent", err)
+	}
+}
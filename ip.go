@@ -1,6 +1,9 @@
 package nexmo
 
-import "net"
+import (
+	"net"
+	"sync"
+)
 
 // IP's sourced from https://help.nexmo.com/entries/23181071-Source-IP-subnet-for-incoming-traffic-in-REST-API
 var masks = []string{
@@ -10,7 +13,10 @@ var masks = []string{
 	"119.81.44.0/28",
 }
 
-var subnets []*net.IPNet
+var (
+	subnetsMutex sync.RWMutex
+	subnets      []*net.IPNet
+)
 
 func init() {
 	subnets = make([]*net.IPNet, len(masks))
@@ -20,10 +26,30 @@ func init() {
 	}
 }
 
-// IsTrustedIP returns true if the provided IP address came from
-// a trusted Nexmo server.
-func IsTrustedIP(ipStr string) bool {
-	ip := net.ParseIP(ipStr)
+// SetNexmoSourceRanges replaces the subnets, in CIDR notation, that Nexmo
+// sends webhooks from. Use it when Nexmo publishes new ranges before this
+// package is updated.
+func SetNexmoSourceRanges(cidrs []string) error {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		nets[i] = n
+	}
+
+	subnetsMutex.Lock()
+	subnets = nets
+	subnetsMutex.Unlock()
+	return nil
+}
+
+// IsNexmoSourceIP returns true if ip is in one of the subnets Nexmo sends
+// webhooks from.
+func IsNexmoSourceIP(ip net.IP) bool {
+	subnetsMutex.RLock()
+	defer subnetsMutex.RUnlock()
 
 	for _, net := range subnets {
 		if net.Contains(ip) {
@@ -32,3 +58,9 @@ func IsTrustedIP(ipStr string) bool {
 	}
 	return false
 }
+
+// IsTrustedIP returns true if the provided IP address came from
+// a trusted Nexmo server.
+func IsTrustedIP(ipStr string) bool {
+	return IsNexmoSourceIP(net.ParseIP(ipStr))
+}
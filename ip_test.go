@@ -1,6 +1,9 @@
 package nexmo
 
-import "testing"
+import (
+	"net"
+	"testing"
+)
 
 var isTrustedIPTests = []struct {
 	ip   string
@@ -23,3 +26,24 @@ func TestIsTrustedIP(t *testing.T) {
 		}
 	}
 }
+
+func TestSetNexmoSourceRanges(t *testing.T) {
+	defer SetNexmoSourceRanges(masks)
+
+	if err := SetNexmoSourceRanges([]string{"not a cidr"}); err == nil {
+		t.Error("Expected error for invalid CIDR")
+	}
+	if !IsNexmoSourceIP(net.ParseIP("174.37.245.33")) {
+		t.Error("Invalid ranges replaced the existing ones")
+	}
+
+	if err := SetNexmoSourceRanges([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal("Failed to set ranges with error:", err)
+	}
+	if !IsNexmoSourceIP(net.ParseIP("10.1.2.3")) {
+		t.Error("New range was not used")
+	}
+	if IsNexmoSourceIP(net.ParseIP("174.37.245.33")) {
+		t.Error("Old range is still used")
+	}
+}
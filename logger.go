@@ -0,0 +1,47 @@
+package nexmo
+
+import (
+	"context"
+	"log"
+)
+
+// Logger is used for verbose logging. A *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type loggerContextKey struct{}
+
+type contextLogger struct {
+	logger  Logger
+	traceID string
+}
+
+// WithLogger returns a copy of ctx that makes requests made with it log to
+// logger, with every line tagged with traceID. Logging happens whether or not
+// Client.VerboseLogging is set.
+func WithLogger(ctx context.Context, logger Logger, traceID string) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, contextLogger{logger, traceID})
+}
+
+// logf logs a line for a request made with ctx. It goes to the logger in ctx
+// if there is one, otherwise to Client.Logger or the standard logger when
+// VerboseLogging is set.
+func (c *Client) logf(ctx context.Context, format string, v ...interface{}) {
+	if cl, ok := ctx.Value(loggerContextKey{}).(contextLogger); ok {
+		if cl.traceID != "" {
+			format = "[" + cl.traceID + "] " + format
+		}
+		cl.logger.Printf("NEXMO: "+format, v...)
+		return
+	}
+
+	if !c.VerboseLogging {
+		return
+	}
+	if c.Logger != nil {
+		c.Logger.Printf("NEXMO: "+format, v...)
+	} else {
+		log.Printf("NEXMO: "+format, v...)
+	}
+}
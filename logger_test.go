@@ -0,0 +1,58 @@
+package nexmo
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogf(t *testing.T) {
+	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	if err != nil {
+		t.Fatal("Failed to create Client with error:", err)
+	}
+
+	var clientLog, contextLog bytes.Buffer
+	nexmo.Logger = log.New(&clientLog, "", 0)
+
+	nexmo.logf(context.Background(), "quiet")
+	if clientLog.Len() != 0 {
+		t.Error("Logged without VerboseLogging:", clientLog.String())
+	}
+
+	ctx := WithLogger(context.Background(), log.New(&contextLog, "", 0), "trace-1")
+	nexmo.logf(ctx, "hello %d", 1)
+	if got := contextLog.String(); got != "NEXMO: [trace-1] hello 1\n" {
+		t.Errorf("Context logger got %q", got)
+	}
+
+	nexmo.VerboseLogging = true
+	nexmo.logf(context.Background(), "verbose")
+	if !strings.Contains(clientLog.String(), "NEXMO: verbose") {
+		t.Errorf("Client logger got %q", clientLog.String())
+	}
+}
+
+func TestContextLoggerRedactsSecret(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.apiSecret = "supersecret"
+
+	var contextLog bytes.Buffer
+	ctx := WithLogger(context.Background(), log.New(&contextLog, "", 0), "trace-1")
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if _, err := nexmo.SMS.SendContext(ctx, msg); err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+
+	if !strings.Contains(contextLog.String(), "Sending encoded form") {
+		t.Fatalf("Context logger got no form line: %q", contextLog.String())
+	}
+	if strings.Contains(contextLog.String(), "supersecret") {
+		t.Errorf("Context logger got the API secret: %q", contextLog.String())
+	}
+}
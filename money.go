@@ -0,0 +1,13 @@
+package nexmo
+
+import "strconv"
+
+// Money is an amount in a currency, such as the price of a message.
+type Money struct {
+	Amount   float64
+	Currency string // ISO 4217 code, e.g. "EUR".
+}
+
+func (m Money) String() string {
+	return strconv.FormatFloat(m.Amount, 'f', -1, 64) + " " + m.Currency
+}
@@ -0,0 +1,243 @@
+package nexmo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// callingCodes maps ISO 3166-1 alpha-2 country codes to their international
+// calling codes. Countries sharing a code (such as the NANP countries under
+// +1) only list the country numbers are attributed to.
+var callingCodes = map[string]string{
+	"US": "1",
+	"RU": "7",
+	"EG": "20",
+	"ZA": "27",
+	"GR": "30",
+	"NL": "31",
+	"BE": "32",
+	"FR": "33",
+	"ES": "34",
+	"HU": "36",
+	"IT": "39",
+	"RO": "40",
+	"CH": "41",
+	"AT": "43",
+	"GB": "44",
+	"DK": "45",
+	"SE": "46",
+	"NO": "47",
+	"PL": "48",
+	"DE": "49",
+	"PE": "51",
+	"MX": "52",
+	"AR": "54",
+	"BR": "55",
+	"CL": "56",
+	"CO": "57",
+	"MY": "60",
+	"AU": "61",
+	"ID": "62",
+	"PH": "63",
+	"NZ": "64",
+	"SG": "65",
+	"TH": "66",
+	"JP": "81",
+	"KR": "82",
+	"VN": "84",
+	"CN": "86",
+	"TR": "90",
+	"IN": "91",
+	"PK": "92",
+	"NG": "234",
+	"KE": "254",
+	"HK": "852",
+	"TW": "886",
+	"AE": "971",
+	"IL": "972",
+	"SA": "966",
+	"PT": "351",
+	"IE": "353",
+	"IS": "354",
+	"FI": "358",
+	"LT": "370",
+	"LV": "371",
+	"EE": "372",
+	"UA": "380",
+	"CZ": "420",
+	"SK": "421",
+}
+
+// trunkPrefixes maps ISO country codes to the trunk prefix that starts
+// national numbers in that country and is dropped in international format.
+// Countries without a trunk prefix, such as Italy, where the leading 0 is
+// part of the number, are not listed.
+var trunkPrefixes = map[string]string{
+	"US": "1",
+	"RU": "8",
+	"EG": "0",
+	"ZA": "0",
+	"NL": "0",
+	"BE": "0",
+	"FR": "0",
+	"HU": "06",
+	"RO": "0",
+	"CH": "0",
+	"AT": "0",
+	"GB": "0",
+	"SE": "0",
+	"DE": "0",
+	"PE": "0",
+	"AR": "0",
+	"BR": "0",
+	"MY": "0",
+	"AU": "0",
+	"ID": "0",
+	"PH": "0",
+	"NZ": "0",
+	"TH": "0",
+	"JP": "0",
+	"KR": "0",
+	"VN": "0",
+	"CN": "0",
+	"TR": "0",
+	"IN": "0",
+	"PK": "0",
+	"NG": "0",
+	"KE": "0",
+	"TW": "0",
+	"AE": "0",
+	"IL": "0",
+	"SA": "0",
+	"IE": "0",
+	"FI": "0",
+	"LT": "8",
+	"UA": "0",
+	"SK": "0",
+}
+
+// ToMSISDN converts a national number, as a user in country would write it,
+// to the international format Nexmo expects, e.g. "07911 123456" in "GB" to
+// "447911123456". Spaces, dashes, dots and parentheses are ignored. A number
+// already starting with + or 00 is only stripped of them and its formatting.
+func ToMSISDN(national, country string) (string, error) {
+	var digits strings.Builder
+	for _, r := range strings.TrimSpace(national) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && digits.Len() == 0:
+			digits.WriteString("00")
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("Invalid character %q in number", r)
+		}
+	}
+	number := digits.String()
+	if strings.HasPrefix(number, "00") {
+		if len(number) <= 2 {
+			return "", errors.New("Invalid number field specified")
+		}
+		return number[2:], nil
+	}
+
+	country = strings.ToUpper(country)
+	code, ok := callingCodes[country]
+	if !ok {
+		return "", fmt.Errorf("Unknown country %q", country)
+	}
+	number = strings.TrimPrefix(number, trunkPrefixes[country])
+	if len(number) <= 0 {
+		return "", errors.New("Invalid number field specified")
+	}
+	return code + number, nil
+}
+
+// countryForMSISDN returns the ISO country code of the international number
+// msisdn, or "" if its calling code isn't known.
+func countryForMSISDN(msisdn string) string {
+	msisdn = strings.TrimPrefix(msisdn, "+")
+	if strings.HasPrefix(msisdn, "00") {
+		msisdn = msisdn[2:]
+	}
+
+	// Calling codes are prefix-free, so at most one will match.
+	for country, code := range callingCodes {
+		if strings.HasPrefix(msisdn, code) {
+			return country
+		}
+	}
+	return ""
+}
+
+// normalizeMSISDN returns the number to, already in international format
+// with or without a + or 00, stripped of its prefix and formatting so equal
+// numbers compare equal. Numbers ToMSISDN can't parse are returned as is.
+func normalizeMSISDN(to string) string {
+	if msisdn, err := ToMSISDN(to, ""); err == nil {
+		return msisdn
+	}
+	if msisdn, err := ToMSISDN("+"+to, ""); err == nil {
+		return msisdn
+	}
+	return to
+}
+
+// ErrLooksNational is returned by Send, with SMS.StrictNumbers set, for a
+// number in To that looks like a national number rather than the
+// international format Nexmo expects.
+var ErrLooksNational = errors.New("Number looks national, not international")
+
+// nationalLengths maps ISO country codes to the number of digits of every
+// mobile number in that country, without the calling code or trunk prefix.
+var nationalLengths = map[string]int{
+	"GB": 10,
+	"US": 10,
+	"FR": 9,
+	"AU": 9,
+	"IN": 10,
+}
+
+// looksNational reports whether to looks like a national number, either
+// because it starts with a single 0, which no calling code does, or because
+// country is "US" and it is a 10 digit number without the calling code.
+func looksNational(to, country string) bool {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(to))
+	if strings.Trim(digits, "0123456789") != "" {
+		return false
+	}
+	if strings.HasPrefix(digits, "0") && !strings.HasPrefix(digits, "00") {
+		return true
+	}
+	return country == "US" && len(digits) == 10 && digits[0] >= '2'
+}
+
+// strictMSISDN returns to, converted to international format from a
+// national number in country if it looks like one. Without a country, or
+// if the number has the wrong length for one, it returns ErrLooksNational.
+func strictMSISDN(to, country string) (string, error) {
+	country = strings.ToUpper(country)
+	if !looksNational(to, country) {
+		return to, nil
+	}
+	if country == "" {
+		return "", fmt.Errorf("%w: %s", ErrLooksNational, to)
+	}
+	msisdn, err := ToMSISDN(to, country)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrLooksNational, to, err)
+	}
+	if length, ok := nationalLengths[country]; ok {
+		if len(msisdn)-len(callingCodes[country]) != length {
+			return "", fmt.Errorf("%w: %s is not a %s number", ErrLooksNational, to, country)
+		}
+	}
+	return msisdn, nil
+}
@@ -0,0 +1,106 @@
+package nexmo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var countryForMSISDNTests = []struct {
+	msisdn string
+	want   string
+}{
+	{"447911123456", "GB"},
+	{"+447911123456", "GB"},
+	{"00447911123456", "GB"},
+	{"14155550100", "US"},
+	{"358401234567", "FI"},
+	{"35312345678", "IE"},
+	{"9991234", ""},
+	{"", ""},
+}
+
+func TestCountryForMSISDN(t *testing.T) {
+	for _, test := range countryForMSISDNTests {
+		got := countryForMSISDN(test.msisdn)
+		if got != test.want {
+			t.Errorf("countryForMSISDN(%s) = %q, want %q",
+				test.msisdn, got, test.want)
+		}
+	}
+}
+
+func TestCallingCodesArePrefixFree(t *testing.T) {
+	for country, code := range callingCodes {
+		for other, otherCode := range callingCodes {
+			if country != other && strings.HasPrefix(otherCode, code) {
+				t.Errorf("Calling code %s (%s) is a prefix of %s (%s)",
+					code, country, otherCode, other)
+			}
+		}
+	}
+}
+
+var toMSISDNTests = []struct {
+	national string
+	country  string
+	want     string
+	wantErr  bool
+}{
+	{"07911 123456", "GB", "447911123456", false},
+	{"7911 123456", "GB", "447911123456", false},
+	{"07911-123-456", "gb", "447911123456", false},
+	{"(415) 555-0100", "US", "14155550100", false},
+	{"1 415 555 0100", "US", "14155550100", false},
+	{"040 123 4567", "FI", "358401234567", false},
+	{"06 20 123 4567", "HU", "36201234567", false},
+	{"8 912 345-67-89", "RU", "79123456789", false},
+	{"06 1234 5678", "IT", "390612345678", false},
+	{"+44 7911 123456", "FI", "447911123456", false},
+	{"0044 7911 123456", "", "447911123456", false},
+	{"07911 123456", "XX", "", true},
+	{"0", "GB", "", true},
+	{"0791l 123456", "GB", "", true},
+	{"+", "GB", "", true},
+}
+
+func TestToMSISDN(t *testing.T) {
+	for _, test := range toMSISDNTests {
+		got, err := ToMSISDN(test.national, test.country)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ToMSISDN(%q, %q) = %q, %v, want %q (error: %v)",
+				test.national, test.country, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+var strictMSISDNTests = []struct {
+	to      string
+	country string
+	want    string
+	wantErr bool
+}{
+	{"447911123456", "", "447911123456", false},
+	{"+447911123456", "GB", "+447911123456", false},
+	{"07911 123456", "GB", "447911123456", false},
+	{"07911 123456", "", "", true},
+	{"0791 123456", "GB", "", true},
+	{"(415) 555-0100", "US", "14155550100", false},
+	{"4155550100", "", "4155550100", false},
+	{"0612345678", "fr", "33612345678", false},
+	{"0612345678", "XX", "", true},
+	{"06 1234 5678", "IT", "390612345678", false},
+}
+
+func TestStrictMSISDN(t *testing.T) {
+	for _, test := range strictMSISDNTests {
+		got, err := strictMSISDN(test.to, test.country)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("strictMSISDN(%q, %q) = %q, %v, want %q (error: %v)",
+				test.to, test.country, got, err, test.want, test.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrLooksNational) {
+			t.Errorf("strictMSISDN(%q, %q) = %v, want ErrLooksNational", test.to, test.country, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package nexmo
+
+// Languages with GSM 03.38 national language shift tables, for
+// SMSMessage.Language.
+const (
+	// LanguageTurkish uses the Turkish locking and single shift tables,
+	// which add ı, İ, ğ, Ğ, ş, Ş and ç in place of some Western European
+	// letters.
+	LanguageTurkish = "tr"
+
+	// LanguageSpanish uses the Spanish single shift table, which adds the
+	// acute accented vowels.
+	LanguageSpanish = "es"
+
+	// LanguagePortuguese uses the Portuguese single shift table, which adds
+	// the accented vowels and ç.
+	LanguagePortuguese = "pt"
+)
+
+// Segment sizes when the national language identifiers take room in the
+// user data header: three octets for a single shift table, three more for a
+// locking shift table, on top of the header length and any concatenation
+// header.
+const (
+	singleShiftSingleSeptets  = 155
+	singleShiftConcatSeptets  = 149
+	lockingShiftSingleSeptets = 152
+	lockingShiftConcatSeptets = 146
+)
+
+// nationalAlphabets maps each of the Language constants to its alphabet.
+var nationalAlphabets = map[string]*gsm7Alphabet{}
+
+func init() {
+	// The Turkish locking shift table is the default alphabet with eight
+	// characters replaced.
+	turkish := map[rune]bool{}
+	for r := range gsm7Basic {
+		turkish[r] = true
+	}
+	for _, r := range "èìØøÆæ¡¿" {
+		delete(turkish, r)
+	}
+	for _, r := range "€ıĞğŞşİç" {
+		turkish[r] = true
+	}
+
+	nationalAlphabets[LanguageTurkish] = &gsm7Alphabet{
+		turkish, shiftTable("ĞİŞç€ğış"),
+		lockingShiftSingleSeptets, lockingShiftConcatSeptets,
+	}
+	nationalAlphabets[LanguageSpanish] = &gsm7Alphabet{
+		gsm7Basic, shiftTable("ÇÁÍÓÚáíóú€"),
+		singleShiftSingleSeptets, singleShiftConcatSeptets,
+	}
+	nationalAlphabets[LanguagePortuguese] = &gsm7Alphabet{
+		gsm7Basic, shiftTable("êçÔôÁáΦΓΩΠΨΣΘÊÀÍÓÚÃÕÂ€íóúãõâ"),
+		singleShiftSingleSeptets, singleShiftConcatSeptets,
+	}
+}
+
+// shiftTable returns a single shift table holding the characters common to
+// every table, the form feed, ^, braces, brackets, backslash, tilde and
+// vertical bar, and extra.
+func shiftTable(extra string) map[rune]bool {
+	table := map[rune]bool{}
+	for _, r := range "\f^{}\\[~]|" + extra {
+		table[r] = true
+	}
+	return table
+}
+
+// alphabet returns the GSM-7 alphabet the message text is counted in when
+// it doesn't fit the default one.
+func (msg *SMSMessage) alphabet() *gsm7Alphabet {
+	if a, ok := nationalAlphabets[msg.Language]; ok {
+		return a
+	}
+	return defaultAlphabet
+}
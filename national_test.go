@@ -0,0 +1,71 @@
+package nexmo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var nationalSegmentTests = []struct {
+	language     string
+	text         string
+	wantSegments int
+	wantEncoding string // Sent in, whatever the language.
+}{
+	{"", "Günaydın, nasılsınız?", 1, EncodingUCS2},
+	{LanguageTurkish, "Günaydın, nasılsınız?", 1, EncodingUCS2},
+	{LanguageTurkish, "Şişli'de çay içtik", 1, EncodingUCS2},
+	{LanguageTurkish, strings.Repeat("ı", 152), 1, EncodingUCS2},
+	{LanguageTurkish, strings.Repeat("ı", 153), 2, EncodingUCS2},
+	{LanguageTurkish, strings.Repeat("ı", 292), 2, EncodingUCS2},
+	{LanguageTurkish, strings.Repeat("ı", 293), 3, EncodingUCS2},
+	{LanguageTurkish, "Ğ", 1, EncodingUCS2},
+	{LanguageTurkish, "Æ", 1, EncodingGSM7}, // Replaced by Ş in the locking shift table.
+	{LanguageTurkish, strings.Repeat("è", 100), 1, EncodingGSM7},
+	{"", "¿Cómo estás? Mañana a las ocho", 1, EncodingUCS2},
+	{LanguageSpanish, "¿Cómo estás? Mañana a las ocho", 1, EncodingUCS2},
+	{LanguageSpanish, strings.Repeat("a", 158), 1, EncodingGSM7}, // The default alphabet needs no shift table.
+	{LanguageSpanish, strings.Repeat("a", 161), 2, EncodingGSM7},
+	{LanguageSpanish, strings.Repeat("á", 77), 1, EncodingUCS2},
+	{LanguageSpanish, strings.Repeat("á", 78), 2, EncodingUCS2},
+	{LanguagePortuguese, "Olá, não há nada aqui, você está ótimo", 1, EncodingUCS2},
+	{LanguagePortuguese, "Привет", 1, EncodingUCS2},
+}
+
+func TestNationalLanguageSegments(t *testing.T) {
+	for _, test := range nationalSegmentTests {
+		msg := &SMSMessage{Type: Text, Text: test.text, Language: test.language}
+		if got := msg.SegmentCount(); got != test.wantSegments {
+			t.Errorf("SegmentCount() of %q in %q = %d, want %d",
+				test.text, test.language, got, test.wantSegments)
+		}
+		if got := msg.usedEncoding(); got != test.wantEncoding {
+			t.Errorf("Encoding of %q in %q = %s, want %s",
+				test.text, test.language, got, test.wantEncoding)
+		}
+	}
+}
+
+func TestSendNationalLanguage(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Test mode sent a request to Nexmo")
+	}))
+	nexmo.SMS.TestMode = true
+	nexmo.SMS.AutoUnicode = true
+
+	msg := &SMSMessage{From: "gonexmo", To: "905551234567", Type: Text,
+		Text: "Günaydın", Language: LanguageTurkish}
+	resp, err := nexmo.SMS.Send(msg)
+	if err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	// Nexmo can't send the national tables, so the text must go as Unicode.
+	if msg.Type != Unicode || resp.UsedEncoding != EncodingUCS2 {
+		t.Errorf("Turkish text was sent as %s in %s, want Unicode in UCS-2", msg.Type, resp.UsedEncoding)
+	}
+
+	msg.Language = "xx"
+	if _, err := nexmo.SMS.Send(msg); err == nil {
+		t.Error("Expected an error for an unknown language")
+	}
+}
@@ -0,0 +1,146 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NexmoTime is a time as sent by Nexmo, which uses several formats:
+//
+//	2006-01-02 15:04:05      message timestamps, in UTC
+//	0601021504               SCTS in delivery receipts, in UTC
+//	0601021504+08            SCTS with its zone in quarter hours
+//	2006-01-02T15:04:05Z     RFC 3339, used by the Reports API
+//	1136214245               Unix time, as a JSON number
+//
+// It decodes from any of them, and encodes in the first.
+type NexmoTime struct {
+	time.Time
+}
+
+// ParseNexmoTime parses a time in any of the string formats of NexmoTime,
+// reading those without a zone as UTC.
+func ParseNexmoTime(s string) (NexmoTime, error) {
+	return ParseNexmoTimeInLocation(s, time.UTC)
+}
+
+// ParseNexmoTimeInLocation is like ParseNexmoTime, but reads times without a
+// zone in loc.
+func ParseNexmoTimeInLocation(s string, loc *time.Location) (NexmoTime, error) {
+	if t, err := time.ParseInLocation(TimeFormat, s, loc); err == nil {
+		return NexmoTime{t}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return NexmoTime{t}, nil
+	}
+	if t, ok := parseSCTS(s, loc); ok {
+		return NexmoTime{t}, nil
+	}
+	return NexmoTime{}, fmt.Errorf("Unrecognised time %q", s)
+}
+
+// parseSCTS parses a service centre timestamp, YYMMDDhhmm with optional
+// seconds, optionally followed by the offset from UTC in quarter hours. One
+// without an offset is read in loc.
+func parseSCTS(s string, loc *time.Location) (time.Time, bool) {
+	digits := len(s)
+	offset := 0
+	zone := loc
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		quarters, err := strconv.Atoi(s[i+1:])
+		if err != nil || len(s)-i != 3 {
+			return time.Time{}, false
+		}
+		offset = quarters * 15 * 60
+		if s[i] == '-' {
+			offset = -offset
+		}
+		digits = i
+		zone = time.UTC
+		if offset != 0 {
+			zone = time.FixedZone("", offset)
+		}
+	}
+
+	layout := ""
+	switch digits {
+	case 10:
+		layout = "0601021504"
+	case 12:
+		layout = "060102150405"
+	default:
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(layout, s[:digits], zone)
+	return t, err == nil
+}
+
+// zonelessUTC is the location of times decoded from JSON in a format
+// without a zone, so that localTime can read them in Client.Location
+// instead. It is otherwise the same as time.UTC.
+var zonelessUTC = time.FixedZone("UTC", 0)
+
+// location returns the Client's Location, or UTC if it isn't set.
+func (c *Client) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+// localTime returns t in the Client's Location, if set. A time decoded
+// without a zone is read in Location rather than converted to it.
+func (c *Client) localTime(t NexmoTime) NexmoTime {
+	if c.Location == nil || t.IsZero() {
+		return t
+	}
+	if t.Location() == zonelessUTC {
+		return NexmoTime{time.Date(t.Year(), t.Month(), t.Day(),
+			t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), c.Location)}
+	}
+	return NexmoTime{t.In(c.Location)}
+}
+
+// UnmarshalJSON decodes a string in any of the formats of NexmoTime, or a
+// number of seconds since the Unix epoch. null and "" leave t zero.
+func (t *NexmoTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] != '"' {
+		var seconds float64
+		if err := json.Unmarshal(data, &seconds); err != nil {
+			return err
+		}
+		whole := int64(seconds)
+		t.Time = time.Unix(whole, int64((seconds-float64(whole))*1e9)).UTC()
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*t = NexmoTime{}
+		return nil
+	}
+	parsed, err := ParseNexmoTimeInLocation(s, zonelessUTC)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON encodes t as "2006-01-02 15:04:05" in UTC, or null if t is
+// zero.
+func (t NexmoTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(TimeFormat))
+}
@@ -0,0 +1,107 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var parseNexmoTimeTests = []struct {
+	s       string
+	want    time.Time
+	wantErr bool
+}{
+	{"2020-01-02 15:04:05", time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC), false},
+	{"2001021504", time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC), false},
+	{"200102150405", time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC), false},
+	{"2001021504+08", time.Date(2020, 1, 2, 13, 4, 0, 0, time.UTC), false},
+	{"2001021504-02", time.Date(2020, 1, 2, 15, 34, 0, 0, time.UTC), false},
+	{"2001021504+00", time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC), false},
+	{"2020-01-02T15:04:05Z", time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC), false},
+	{"2020-01-02T15:04:05.250Z", time.Date(2020, 1, 2, 15, 4, 5, 250e6, time.UTC), false},
+	{"2020-01-02T17:04:05+02:00", time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC), false},
+	{"2001021504+8", time.Time{}, true},
+	{"20010215", time.Time{}, true},
+	{"yesterday", time.Time{}, true},
+}
+
+func TestParseNexmoTime(t *testing.T) {
+	for _, test := range parseNexmoTimeTests {
+		got, err := ParseNexmoTime(test.s)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseNexmoTime(%q) error = %v, want error: %v", test.s, err, test.wantErr)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("ParseNexmoTime(%q) = %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestNexmoTimeJSON(t *testing.T) {
+	var v struct {
+		A, B, C, D NexmoTime
+	}
+	body := `{"A": "2020-01-02 15:04:05", "B": 1577977445, "C": "2001021504+08", "D": null}`
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		t.Fatal("Unmarshal failed with error:", err)
+	}
+	want := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	if !v.A.Equal(want) || !v.B.Equal(want) {
+		t.Errorf("Decoded %v and %v, want %v", v.A, v.B, want)
+	}
+	if _, offset := v.C.Zone(); offset != 2*60*60 {
+		t.Errorf("SCTS zone offset = %ds, want 7200s", offset)
+	}
+	if !v.D.IsZero() {
+		t.Errorf("null decoded as %v, want the zero time", v.D)
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal("Marshal failed with error:", err)
+	}
+	wantJSON := `{"A":"2020-01-02 15:04:05","B":"2020-01-02 15:04:05","C":"2020-01-02 13:04:00","D":null}`
+	if string(encoded) != wantJSON {
+		t.Errorf("Marshal = %s, want %s", encoded, wantJSON)
+	}
+}
+
+func TestParseNexmoTimeInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"2020-01-02 15:04:05", time.Date(2020, 1, 2, 15, 4, 5, 0, loc)},
+		{"2001021504", time.Date(2020, 1, 2, 15, 4, 0, 0, loc)},
+		{"2001021504+00", time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2020-01-02T15:04:05Z", time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		got, err := ParseNexmoTimeInLocation(test.s, loc)
+		if err != nil || !got.Equal(test.want) {
+			t.Errorf("ParseNexmoTimeInLocation(%q) = %v, %v, want %v", test.s, got, err, test.want)
+		}
+	}
+}
+
+func TestMessageHandlerLocation(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.NotFoundHandler())
+	nexmo.Location = time.FixedZone("UTC+8", 8*60*60)
+
+	messages := make(chan *RecvdMessage, 2)
+	query := "/?type=text&text=Hello&msisdn=447700900001&to=447700900000&message-timestamp=2020-01-02+15%3A04%3A05"
+	for _, h := range []http.HandlerFunc{NewMessageHandler(messages, false), nexmo.SMS.NewMessageHandler(messages, false)} {
+		h(httptest.NewRecorder(), httptest.NewRequest("GET", query, nil))
+	}
+
+	if m := <-messages; !m.Timestamp.Equal(time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("Package level handler timestamp = %v, want it in UTC", m.Timestamp)
+	}
+	if m := <-messages; !m.Timestamp.Equal(time.Date(2020, 1, 2, 15, 4, 5, 0, nexmo.Location)) {
+		t.Errorf("SMS handler timestamp = %v, want it in UTC+8", m.Timestamp)
+	}
+}
@@ -1,22 +1,91 @@
 package nexmo
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// numbersInterval is the minimum time between two requests to the number
+// management API, which only allows one request per second.
+const numbersInterval = time.Second
+
 // Numbers represents the number management API functions
 type Numbers struct {
 	client *Client
+
+	// BatchConcurrency is the number of updates UpdateMany sends at once.
+	// Values below one mean one. Requests are still spaced a second apart,
+	// so this only helps when responses are slow.
+	BatchConcurrency int
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until another request may be sent to the number management API,
+// or ctx is done. Each caller reserves the next free slot before waiting, so
+// the lock isn't held while it sleeps.
+func (c *Numbers) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := c.client.clock.Now()
+	slot := c.next
+	if slot.Before(now) {
+		slot = now
+	}
+	c.next = slot.Add(numbersInterval)
+	c.mu.Unlock()
+
+	if !slot.After(now) {
+		return nil
+	}
+	select {
+	case <-c.client.clock.After(slot.Sub(now)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // Type NumberSearchOptions defines options for filtering when searching for available numbers to purchase
 type NumberSearchOptions struct {
 	Pattern       string
 	SearchPattern string
+
+	// Size is the number of results per page, up to 100. Index is the page
+	// to return, starting at 1. Nexmo defaults to the first page of 10.
+	Size  int
+	Index int
+
+	// MaxCost, if greater than zero, leaves out available numbers whose
+	// Cost is higher. Nexmo can't filter by cost, so this is done after
+	// each page is fetched; pages may hold fewer than Size numbers and the
+	// response Count is unaffected.
+	MaxCost float64
+}
+
+// values returns opts as query parameters.
+func (opts NumberSearchOptions) values() url.Values {
+	values := url.Values{}
+	if opts.Pattern != "" && opts.SearchPattern != "" {
+		values.Set("pattern", opts.Pattern)
+		values.Set("search_pattern", opts.SearchPattern)
+	}
+	if opts.Size > 0 {
+		values.Set("size", strconv.Itoa(opts.Size))
+	}
+	if opts.Index > 0 {
+		values.Set("index", strconv.Itoa(opts.Index))
+	}
+	return values
 }
 
 // Type NumberSearchResponse represents a set of phone number available for purchase, and their count
@@ -31,7 +100,7 @@ type AvailableNumber struct {
 	MSISDN   string
 	Type     string
 	Features []string
-	Cost     float64 `json:",string"`
+	Cost     float64 `json:",string"` // Monthly rental cost, in the account currency.
 }
 
 /*
@@ -39,43 +108,192 @@ type AvailableNumber struct {
 	{"count":count,"numbers":[{"country":"country-code","msisdn":"phone number","type":"type of number","features":["feature"],"cost":"number cost"}]}
 */
 
-// Search for available phone numbers in a given country
+// Search for available phone numbers in a given country. A country with no
+// numbers available is not an error: the response has a Count of 0 and an
+// empty, non-nil Numbers.
 func (c *Numbers) SearchAvailable(countryCode string) (response NumberSearchResponse, err error) {
 	return c.SearchAvailableWithOptions(countryCode, NumberSearchOptions{})
 }
 
 // Search for available phone numbers in a given country, filtering by a pattern
-func (c *Numbers) SearchAvailableWithOptions(countryCode string, opts NumberSearchOptions) (response NumberSearchResponse, err error) {
+// and cost
+func (c *Numbers) SearchAvailableWithOptions(countryCode string, opts NumberSearchOptions) (NumberSearchResponse, error) {
+	response, err := c.searchAvailable(context.Background(), countryCode, opts)
+	if err == nil {
+		response.Numbers = opts.affordable(response.Numbers)
+	}
+	return response, err
+}
+
+// affordable returns the numbers that cost no more than MaxCost.
+func (opts NumberSearchOptions) affordable(numbers []AvailableNumber) []AvailableNumber {
+	if opts.MaxCost <= 0 {
+		return numbers
+	}
+	filtered := []AvailableNumber{}
+	for _, n := range numbers {
+		if n.Cost <= opts.MaxCost {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// searchAvailable fetches one page of available numbers with ctx, without
+// filtering them by cost.
+func (c *Numbers) searchAvailable(ctx context.Context, countryCode string, opts NumberSearchOptions) (response NumberSearchResponse, err error) {
 	if len(countryCode) <= 0 {
 		err = errors.New("Invalid country code field specified")
 		return
 	}
 
-	client := &http.Client{}
+	requestUrl := c.client.restURL + "/number/search/" + c.client.apiKey + "/" + c.client.apiSecret + "/" + countryCode
+	if query := opts.values().Encode(); query != "" {
+		requestUrl += "?" + query
+	}
 
-	requestUrl := apiRoot + "/number/search/" + c.client.apiKey + "/" + c.client.apiSecret + "/" + countryCode
-	if opts.Pattern != "" && opts.SearchPattern != "" {
-		requestUrl += "?pattern=" + url.QueryEscape(opts.Pattern)
-		if opts.SearchPattern != "" {
-			requestUrl += "&search_pattern=" + url.QueryEscape(opts.SearchPattern)
+	r, _ := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
+
+	defer c.client.wrapError(r, &err)
+	if err = c.wait(ctx); err != nil {
+		return
+	}
+	resp, err := c.client.do(r)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	err = json.Unmarshal(body, &response)
+	if err == nil && response.Numbers == nil {
+		response.Numbers = []AvailableNumber{}
+	}
+	return
+
+}
+
+// Number features, as listed in AvailableNumber.Features and
+// OwnedNumber.Features.
+const (
+	FeatureSMS   = "SMS"
+	FeatureVoice = "VOICE"
+	FeatureMMS   = "MMS"
+)
+
+// Type OwnedNumber represents a phone number owned by the account
+type OwnedNumber struct {
+	Country                string   `json:"country"`
+	MSISDN                 string   `json:"msisdn"`
+	Type                   string   `json:"type"`
+	Features               []string `json:"features"`
+	MoHttpUrl              string   `json:"moHttpUrl"`
+	VoiceCallbackType      string   `json:"voiceCallbackType"`
+	VoiceCallbackValue     string   `json:"voiceCallbackValue"`
+	VoiceStatusCallbackUrl string   `json:"voiceStatusCallbackUrl"`
+	AppID                  string   `json:"app_id"`
+}
+
+// Type OwnedNumbersResponse represents a set of phone numbers owned by the
+// account, and their count
+type OwnedNumbersResponse struct {
+	Count   int64
+	Numbers []OwnedNumber
+}
+
+// HasFeature reports whether n supports feature f, e.g. FeatureVoice. The
+// features are those Nexmo has activated on the number, which may differ from
+// the ones listed when it was found with SearchAvailable.
+func HasFeature(n OwnedNumber, f string) bool {
+	for _, feature := range n.Features {
+		if strings.EqualFold(feature, f) {
+			return true
 		}
 	}
+	return false
+}
+
+// hasFeatures reports whether n has every one of features, or any feature
+// at all if features is empty.
+func hasFeatures(n OwnedNumber, features []string) bool {
+	if len(features) == 0 {
+		return len(n.Features) > 0
+	}
+	for _, f := range features {
+		if !HasFeature(n, f) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+	GET /account/numbers/{api_key}/{api_secret}?pattern={pattern}&search_pattern={search_pattern}
+	{"count":count,"numbers":[{"country":"country-code","msisdn":"phone number","type":"type of number","features":["feature"],"moHttpUrl":"url"}]}
+*/
+
+// List the phone numbers owned by the account
+func (c *Numbers) List() (OwnedNumbersResponse, error) {
+	return c.ListWithOptions(NumberSearchOptions{})
+}
+
+// List the phone numbers owned by the account, filtering by a pattern
+func (c *Numbers) ListWithOptions(opts NumberSearchOptions) (OwnedNumbersResponse, error) {
+	return c.listOwned(context.Background(), opts)
+}
+
+// listOwned is ListWithOptions with the request made with ctx.
+func (c *Numbers) listOwned(ctx context.Context, opts NumberSearchOptions) (response OwnedNumbersResponse, err error) {
+	requestUrl := c.client.restURL + "/account/numbers/" + c.client.apiKey + "/" + c.client.apiSecret
+	if query := opts.values().Encode(); query != "" {
+		requestUrl += "?" + query
+	}
 
 	r, _ := http.NewRequest("GET", requestUrl, nil)
-	r.Header.Add("Accept", "application/json")
+	r = r.WithContext(ctx)
 
-	resp, err := client.Do(r)
+	defer c.client.wrapError(r, &err)
+	if err = c.wait(r.Context()); err != nil {
+		return
+	}
+	resp, err := c.client.do(r)
+	if err != nil {
+		return
+	}
 	defer resp.Body.Close()
 
-	if err != nil {
+	if resp.StatusCode != 200 {
+		err = fmt.Errorf("Unexpected response status %d", resp.StatusCode)
 		return
 	}
 
 	body, _ := ioutil.ReadAll(resp.Body)
 
 	err = json.Unmarshal(body, &response)
+	if err == nil && response.Numbers == nil {
+		response.Numbers = []OwnedNumber{}
+	}
 	return
+}
+
+// Get returns the owned phone number msisdn, with its features and webhooks
+func (c *Numbers) Get(msisdn string) (*OwnedNumber, error) {
+	if len(msisdn) <= 0 {
+		return nil, errors.New("Invalid number field specified")
+	}
 
+	// search_pattern 0 matches numbers starting with the pattern.
+	response, err := c.ListWithOptions(NumberSearchOptions{Pattern: msisdn, SearchPattern: "0"})
+	if err != nil {
+		return nil, err
+	}
+	for i := range response.Numbers {
+		if response.Numbers[i].MSISDN == msisdn {
+			return &response.Numbers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Number %s is not owned by the account", msisdn)
 }
 
 /*
@@ -85,6 +303,11 @@ func (c *Numbers) SearchAvailableWithOptions(countryCode string, opts NumberSear
 
 // Buy a phone number
 func (c *Numbers) BuyPhoneNumber(countryCode, number string) (bool, error) {
+	return c.buy(context.Background(), countryCode, number)
+}
+
+// buy is BuyPhoneNumber with the request made with ctx.
+func (c *Numbers) buy(ctx context.Context, countryCode, number string) (bought bool, err error) {
 	if len(countryCode) <= 0 {
 		return false, errors.New("Invalid country code field specified")
 	}
@@ -93,19 +316,20 @@ func (c *Numbers) BuyPhoneNumber(countryCode, number string) (bool, error) {
 		return false, errors.New("Invalid number field specified")
 	}
 
-	client := &http.Client{}
-
-	requestUrl := apiRoot + "/number/buy/" + c.client.apiKey + "/" +
+	requestUrl := c.client.restURL + "/number/buy/" + c.client.apiKey + "/" +
 		c.client.apiSecret + "/" + countryCode + "/" + number
 	r, _ := http.NewRequest("POST", requestUrl, nil)
-	r.Header.Add("Accept", "application/json")
-
-	resp, err := client.Do(r)
-	defer resp.Body.Close()
+	r = r.WithContext(ctx)
 
+	defer c.client.wrapError(r, &err)
+	if err = c.wait(r.Context()); err != nil {
+		return false, err
+	}
+	resp, err := c.client.do(r)
 	if err != nil {
 		return false, err
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case 200:
@@ -115,7 +339,56 @@ func (c *Numbers) BuyPhoneNumber(countryCode, number string) (bool, error) {
 	case 420:
 		return false, errors.New("Bad parameters")
 	default:
-		return false, errors.New("Other error")
+		return false, fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+	}
+}
+
+// buyConfirmInterval is how often BuyAndConfirm checks whether a number it
+// bought has been provisioned.
+const buyConfirmInterval = 5 * time.Second
+
+// ErrNotProvisioned is returned by BuyAndConfirm when a number was bought
+// but didn't become usable before the timeout.
+var ErrNotProvisioned = errors.New("Number was bought but is not provisioned yet")
+
+// BuyAndConfirm buys the number and waits until it is listed among the
+// account's numbers with every one of features, e.g. FeatureSMS, which can
+// lag behind the purchase, so webhooks can be set up on it straight away.
+// If features is empty, any feature will do. It returns the owned number,
+// or ErrNotProvisioned if it isn't ready within timeout. The number stays
+// bought either way.
+func (c *Numbers) BuyAndConfirm(ctx context.Context, countryCode, number string, features []string, timeout time.Duration) (*OwnedNumber, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := c.buy(ctx, countryCode, number); err != nil {
+		return nil, err
+	}
+
+	deadline := c.client.clock.Now().Add(timeout)
+	for {
+		response, err := c.listOwned(ctx, NumberSearchOptions{Pattern: number, SearchPattern: "0"})
+		if err != nil {
+			return nil, err
+		}
+		for i := range response.Numbers {
+			owned := &response.Numbers[i]
+			if owned.MSISDN == number && hasFeatures(*owned, features) {
+				return owned, nil
+			}
+		}
+
+		wait := buyConfirmInterval
+		if remaining := deadline.Sub(c.client.clock.Now()); remaining <= 0 {
+			return nil, ErrNotProvisioned
+		} else if remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-c.client.clock.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
 }
 
@@ -125,7 +398,7 @@ func (c *Numbers) BuyPhoneNumber(countryCode, number string) (bool, error) {
 */
 
 // Cancel a phone number
-func (c *Numbers) CancelPhoneNumber(countryCode, number string) (bool, error) {
+func (c *Numbers) CancelPhoneNumber(countryCode, number string) (cancelled bool, err error) {
 	if len(countryCode) <= 0 {
 		return false, errors.New("Invalid country code field specified")
 	}
@@ -134,19 +407,19 @@ func (c *Numbers) CancelPhoneNumber(countryCode, number string) (bool, error) {
 		return false, errors.New("Invalid number field specified")
 	}
 
-	client := &http.Client{}
-
-	requestUrl := apiRoot + "/number/cancel/" + c.client.apiKey + "/" +
+	requestUrl := c.client.restURL + "/number/cancel/" + c.client.apiKey + "/" +
 		c.client.apiSecret + "/" + countryCode + "/" + number
 	r, _ := http.NewRequest("POST", requestUrl, nil)
-	r.Header.Add("Accept", "application/json")
-
-	resp, err := client.Do(r)
-	defer resp.Body.Close()
 
+	defer c.client.wrapError(r, &err)
+	if err = c.wait(r.Context()); err != nil {
+		return false, err
+	}
+	resp, err := c.client.do(r)
 	if err != nil {
 		return false, err
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case 200:
@@ -156,21 +429,91 @@ func (c *Numbers) CancelPhoneNumber(countryCode, number string) (bool, error) {
 	case 420:
 		return false, errors.New("Bad parameters")
 	default:
-		return false, errors.New("Other error")
+		return false, fmt.Errorf("Unexpected response status %d", resp.StatusCode)
 	}
 }
 
 /*
-	POST /number/update/{api_key}/{api_secret}/{country}/{msisdn}?moHttpUrl={url}&moSmppSysType={sysType}&voiceCallbackType={type}&voiceCallbackValue={value}&voiceStatusCallback={status}
-	POST /number/update?api_key={api_key}&api_secret={api_secret}&country={country}&msisdn={msisdn}&moHttpUrl={url}&moSmppSysType={sysType}&voiceCallbackType={type}&voiceCallbackValue={value}&voiceStatusCallback={status}
+	POST /number/update/{api_key}/{api_secret}/{country}/{msisdn}?moHttpUrl={url}&moSmppSysType={sysType}&voiceCallbackType={type}&voiceCallbackValue={value}&voiceStatusCallback={status}&app_id={app_id}
+	POST /number/update?api_key={api_key}&api_secret={api_secret}&country={country}&msisdn={msisdn}&moHttpUrl={url}&moSmppSysType={sysType}&voiceCallbackType={type}&voiceCallbackValue={value}&voiceStatusCallback={status}&app_id={app_id}
 */
 
 type UpdateNumberOpts struct {
 	MoHttpUrl string
+	AppID     string
 }
 
 // Update a phone number with webhook URLs
 func (c *Numbers) UpdateNumber(countryCode, number string, opts UpdateNumberOpts) (bool, error) {
+	values := url.Values{}
+	if opts.MoHttpUrl != "" {
+		values.Set("moHttpUrl", opts.MoHttpUrl)
+	}
+	if opts.AppID != "" {
+		values.Set("app_id", opts.AppID)
+	}
+	return c.update(countryCode, number, values)
+}
+
+// LinkApplication links a phone number to the application appID, so that
+// the application's webhooks receive its calls and messages.
+func (c *Numbers) LinkApplication(countryCode, number, appID string) error {
+	if len(appID) <= 0 {
+		return errors.New("Invalid application ID specified")
+	}
+	values := url.Values{}
+	values.Set("app_id", appID)
+	if _, err := c.update(countryCode, number, values); err != nil {
+		return fmt.Errorf("Could not link %s to application %s, check that both exist: %w",
+			number, appID, err)
+	}
+	return nil
+}
+
+// UnlinkApplication removes the link between a phone number and its
+// application.
+func (c *Numbers) UnlinkApplication(countryCode, number string) error {
+	values := url.Values{}
+	values.Set("app_id", "")
+	if _, err := c.update(countryCode, number, values); err != nil {
+		return fmt.Errorf("Could not unlink %s from its application: %w", number, err)
+	}
+	return nil
+}
+
+// NumberUpdateRequest is an update of a single number for UpdateMany.
+type NumberUpdateRequest struct {
+	Country string
+	MSISDN  string
+	Options UpdateNumberOpts
+}
+
+// UpdateResult is the outcome of updating a single number.
+type UpdateResult struct {
+	Country string
+	MSISDN  string
+	Err     error
+}
+
+// UpdateMany applies every update as UpdateNumber does, with up to
+// BatchConcurrency requests in flight, and returns their results in the same
+// order as updates. A failed update doesn't stop the others; the returned
+// error, if any, only says how many failed, and each failure is in its
+// result's Err.
+func (c *Numbers) UpdateMany(updates []NumberUpdateRequest) ([]UpdateResult, error) {
+	results := make([]UpdateResult, len(updates))
+	runBatch(len(updates), c.BatchConcurrency, func(i int) {
+		update := updates[i]
+		_, err := c.UpdateNumber(update.Country, update.MSISDN, update.Options)
+		results[i] = UpdateResult{Country: update.Country, MSISDN: update.MSISDN, Err: err}
+	})
+	return results, batchError(len(results), "number updates", func(i int) bool {
+		return results[i].Err != nil
+	})
+}
+
+// update sets the given parameters on a phone number.
+func (c *Numbers) update(countryCode, number string, values url.Values) (updated bool, err error) {
 	if len(countryCode) <= 0 {
 		return false, errors.New("Invalid country code field specified")
 	}
@@ -179,24 +522,20 @@ func (c *Numbers) UpdateNumber(countryCode, number string, opts UpdateNumberOpts
 		return false, errors.New("Invalid number field specified")
 	}
 
-	client := &http.Client{}
-
-	requestUrl := apiRoot + "/number/update/" + c.client.apiKey + "/" +
-		c.client.apiSecret + "/" + countryCode + "/" + number + "?"
-
-	if opts.MoHttpUrl != "" {
-		requestUrl += "&moHttpUrl=" + url.QueryEscape(opts.MoHttpUrl)
-	}
+	requestUrl := c.client.restURL + "/number/update/" + c.client.apiKey + "/" +
+		c.client.apiSecret + "/" + countryCode + "/" + number + "?" + values.Encode()
 
 	r, _ := http.NewRequest("POST", requestUrl, nil)
-	r.Header.Add("Accept", "application/json")
-
-	resp, err := client.Do(r)
-	defer resp.Body.Close()
 
+	defer c.client.wrapError(r, &err)
+	if err = c.wait(r.Context()); err != nil {
+		return false, err
+	}
+	resp, err := c.client.do(r)
 	if err != nil {
 		return false, err
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case 200:
@@ -206,6 +545,6 @@ func (c *Numbers) UpdateNumber(countryCode, number string, opts UpdateNumberOpts
 	case 420:
 		return false, errors.New("Bad parameters")
 	default:
-		return false, errors.New("Other error")
+		return false, fmt.Errorf("Unexpected response status %d", resp.StatusCode)
 	}
 }
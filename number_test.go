@@ -1,20 +1,229 @@
 package nexmo
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
-func TestSearchForAvailableNumber(t *testing.T) {
-	time.Sleep(1 * time.Second) // Sleep 1 second due to API limitation
+// testAppID is the only application the fake number management API knows.
+const testAppID = "aaaaaaaa-bbbb-cccc-dddd-0123456789ab"
+
+// numbersServer is a fake of the number management API. It has a fixed set
+// of numbers for sale in the US and records when each request arrived.
+type numbersServer struct {
+	t     *testing.T
+	clock *fakeClock
+
+	mu        sync.Mutex
+	available []AvailableNumber
+	owned     map[string]*OwnedNumber
+	requests  []time.Time
+
+	// provisionDelay is how long bought numbers are listed without their
+	// features, as if still being provisioned.
+	provisionDelay time.Duration
+	provisionedAt  map[string]time.Time
+}
+
+func newNumbersServer(t *testing.T) (*Client, *numbersServer) {
+	s := &numbersServer{
+		t: t,
+		available: []AvailableNumber{
+			{Country: "US", MSISDN: "14155550100", Type: "mobile-lvn", Features: []string{"SMS"}, Cost: 0.9},
+			{Country: "US", MSISDN: "12125551985", Type: "mobile-lvn", Features: []string{"SMS", "VOICE"}, Cost: 0.9},
+		},
+		owned:         map[string]*OwnedNumber{},
+		provisionedAt: map[string]time.Time{},
+	}
+	nexmo, clk := newTestClient(t, s)
+	s.clock = clk
+	return nexmo, s
+}
+
+func (s *numbersServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, s.clock.Now())
 
-	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	// /account/numbers/{api_key}/{api_secret}
+	if len(parts) == 4 && parts[0] == "account" && parts[1] == "numbers" {
+		if parts[2] != API_KEY || parts[3] != API_SECRET {
+			w.WriteHeader(401)
+			return
+		}
+		response := OwnedNumbersResponse{}
+		pattern := r.URL.Query().Get("pattern")
+		for _, n := range s.owned {
+			if !strings.HasPrefix(n.MSISDN, pattern) {
+				continue
+			}
+			listed := *n
+			if s.clock.Now().Before(s.provisionedAt[n.MSISDN]) {
+				listed.Features = nil
+			}
+			response.Numbers = append(response.Numbers, listed)
+		}
+		response.Count = int64(len(response.Numbers))
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// /number/{action}/{api_key}/{api_secret}/{country}[/{msisdn}]
+	if len(parts) < 5 || parts[0] != "number" {
+		http.NotFound(w, r)
+		return
+	}
+	if parts[2] != API_KEY || parts[3] != API_SECRET {
+		w.WriteHeader(401)
+		return
+	}
+	action, country := parts[1], parts[4]
+
+	if action == "search" {
+		response := NumberSearchResponse{}
+		pattern := r.URL.Query().Get("pattern")
+		for _, n := range s.available {
+			if n.Country == country && strings.Contains(n.MSISDN, pattern) {
+				response.Numbers = append(response.Numbers, n)
+			}
+		}
+		response.Count = int64(len(response.Numbers))
+		response.Numbers = page(r, response.Numbers)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if len(parts) != 6 {
+		w.WriteHeader(420)
+		return
+	}
+	msisdn := parts[5]
+	switch action {
+	case "buy":
+		for i, n := range s.available {
+			if n.Country == country && n.MSISDN == msisdn {
+				s.available = append(s.available[:i], s.available[i+1:]...)
+				s.owned[msisdn] = &OwnedNumber{
+					Country:  n.Country,
+					MSISDN:   n.MSISDN,
+					Type:     n.Type,
+					Features: n.Features,
+				}
+				s.provisionedAt[msisdn] = s.clock.Now().Add(s.provisionDelay)
+				return
+			}
+		}
+		w.WriteHeader(420)
+	case "update", "cancel":
+		n, ok := s.owned[msisdn]
+		if !ok {
+			w.WriteHeader(420)
+			return
+		}
+		if action == "cancel" {
+			delete(s.owned, msisdn)
+			return
+		}
+		query := r.URL.Query()
+		if appIDs, ok := query["app_id"]; ok {
+			if appIDs[0] != "" && appIDs[0] != testAppID {
+				w.WriteHeader(420)
+				return
+			}
+			n.AppID = appIDs[0]
+		}
+		if moHttpUrl := query.Get("moHttpUrl"); moHttpUrl != "" {
+			n.MoHttpUrl = moHttpUrl
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// page returns the page of numbers selected by the size and index parameters
+// of r, which default to the first 10.
+func page(r *http.Request, numbers []AvailableNumber) []AvailableNumber {
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
 	if err != nil {
-		t.Error("Failed to create Client with error:", err)
+		size = 10
+	}
+	index, err := strconv.Atoi(r.URL.Query().Get("index"))
+	if err != nil {
+		index = 1
+	}
+	start := (index - 1) * size
+	if start >= len(numbers) {
+		return nil
+	}
+	if start+size > len(numbers) {
+		return numbers[start:]
+	}
+	return numbers[start : start+size]
+}
+
+// checkSpacing fails the test if any two requests reached the server less
+// than numbersInterval apart on the fake clock.
+func (s *numbersServer) checkSpacing() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 1; i < len(s.requests); i++ {
+		if gap := s.requests[i].Sub(s.requests[i-1]); gap < numbersInterval {
+			s.t.Errorf("Requests %d and %d were %v apart, want at least %v", i-1, i, gap, numbersInterval)
+		}
+	}
+}
+
+// stalledClock is a fakeClock whose After never fires.
+type stalledClock struct {
+	*fakeClock
+}
+
+func (stalledClock) After(time.Duration) <-chan time.Time {
+	return nil
+}
+
+func TestNumbersWaitContext(t *testing.T) {
+	nexmo, clk := newTestClient(t, http.NotFoundHandler())
+	nexmo.clock = stalledClock{clk}
+
+	if err := nexmo.Numbers.wait(context.Background()); err != nil {
+		t.Fatal("First wait failed with error:", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	waited := make(chan error, 1)
+	go func() { waited <- nexmo.Numbers.wait(ctx) }()
+
+	// A cancelled wait returns while another is sleeping.
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := nexmo.Numbers.wait(cancelled); err != context.Canceled {
+		t.Errorf("wait with a cancelled context = %v, want context.Canceled", err)
+	}
+
+	cancel()
+	select {
+	case err := <-waited:
+		if err != context.Canceled {
+			t.Errorf("wait cancelled while sleeping = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait didn't return when its context was cancelled")
+	}
+}
+
+func TestSearchForAvailableNumber(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+
 	resp, err := nexmo.Numbers.SearchAvailable("US")
 	if err != nil {
 		t.Error("Unexpected number search error:", err)
@@ -41,7 +250,6 @@ func TestSearchForAvailableNumber(t *testing.T) {
 		}
 	}
 
-	// This test could break if Nexmo's 1985 supply is exhausted
 	pattern := "1985"
 	resp2, err := nexmo.Numbers.SearchAvailableWithOptions("US", NumberSearchOptions{Pattern: pattern, SearchPattern: "0"})
 	if err != nil {
@@ -59,17 +267,21 @@ func TestSearchForAvailableNumber(t *testing.T) {
 			}
 		}
 	}
-}
 
-func TestBuyUpdateAndCancelAvailableNumber(t *testing.T) {
-	time.Sleep(1 * time.Second) // Sleep 1 second due to API limitation
-
-	nexmo, err := NewClientFromAPI(API_KEY, API_SECRET)
+	resp3, err := nexmo.Numbers.SearchAvailable("FI")
 	if err != nil {
-		t.Error("Failed to create Client with error:", err)
+		t.Error("Unexpected number search error:", err)
+	} else if resp3.Count != 0 || resp3.Numbers == nil || len(resp3.Numbers) != 0 {
+		t.Errorf("Search with no results = %+v, want a zero count and empty Numbers", resp3)
 	}
 
-	_, err = nexmo.Numbers.BuyPhoneNumber("", "")
+	server.checkSpacing()
+}
+
+func TestBuyUpdateAndCancelAvailableNumber(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+
+	_, err := nexmo.Numbers.BuyPhoneNumber("", "")
 	if err == nil {
 		t.Error("Expected error with blank parameters")
 	}
@@ -86,12 +298,10 @@ func TestBuyUpdateAndCancelAvailableNumber(t *testing.T) {
 
 	availableNums, err := nexmo.Numbers.SearchAvailable("US")
 	if err != nil || len(availableNums.Numbers) == 0 {
-		t.Error("Could not find available phone numbers. Error:", err)
-		return
+		t.Fatal("Could not find available phone numbers. Error:", err)
 	}
 
 	numToBuy := availableNums.Numbers[0].MSISDN
-	fmt.Println("Buying (and then cancelling) phone number:", numToBuy)
 
 	buySuccess, err := nexmo.Numbers.BuyPhoneNumber("US", numToBuy)
 	if err != nil {
@@ -101,17 +311,26 @@ func TestBuyUpdateAndCancelAvailableNumber(t *testing.T) {
 		t.Error("Purchase was not success")
 	}
 
-	testUpdateUrl := ""
-	if testUpdateUrl != "" {
-		updateOpts := UpdateNumberOpts{
-			MoHttpUrl: testUpdateUrl,
-		}
-		updateSuccess, err := nexmo.Numbers.UpdateNumber("US", numToBuy, updateOpts)
-		if err != nil {
-			t.Error("Error when updating phone number:", err)
+	updateOpts := UpdateNumberOpts{
+		MoHttpUrl: "https://example.com/inbound",
+	}
+	updateSuccess, err := nexmo.Numbers.UpdateNumber("US", numToBuy, updateOpts)
+	if err != nil {
+		t.Error("Error when updating phone number:", err)
+	}
+	if !updateSuccess {
+		t.Error("Update was not success")
+	}
+
+	owned, err := nexmo.Numbers.Get(numToBuy)
+	if err != nil {
+		t.Error("Error when getting phone number:", err)
+	} else {
+		if owned.MoHttpUrl != updateOpts.MoHttpUrl {
+			t.Errorf("Owned number has moHttpUrl %q, want %q", owned.MoHttpUrl, updateOpts.MoHttpUrl)
 		}
-		if !updateSuccess {
-			t.Error("Update was not success")
+		if !HasFeature(*owned, FeatureSMS) {
+			t.Error("Owned number should support SMS")
 		}
 	}
 
@@ -122,4 +341,150 @@ func TestBuyUpdateAndCancelAvailableNumber(t *testing.T) {
 	if !cancelSuccess {
 		t.Error("Cancel was not success")
 	}
+
+	_, err = nexmo.Numbers.CancelPhoneNumber("US", numToBuy)
+	if err == nil {
+		t.Error("Expected error when cancelling a number that is not owned")
+	}
+
+	list, err := nexmo.Numbers.List()
+	if err != nil {
+		t.Error("Error when listing phone numbers:", err)
+	} else if list.Count != 0 || len(list.Numbers) != 0 {
+		t.Errorf("List after cancelling = %+v, want no numbers", list)
+	}
+
+	server.checkSpacing()
+}
+
+func TestUpdateMany(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+	nexmo.Numbers.BatchConcurrency = 2
+
+	for _, msisdn := range []string{"14155550100", "12125551985"} {
+		if _, err := nexmo.Numbers.BuyPhoneNumber("US", msisdn); err != nil {
+			t.Fatal("Error when buying phone number:", err)
+		}
+	}
+
+	const webhook = "https://example.com/inbound"
+	updates := []NumberUpdateRequest{
+		{Country: "US", MSISDN: "14155550100", Options: UpdateNumberOpts{MoHttpUrl: webhook}},
+		{Country: "US", MSISDN: "14155550199", Options: UpdateNumberOpts{MoHttpUrl: webhook}},
+		{Country: "US", MSISDN: "12125551985", Options: UpdateNumberOpts{MoHttpUrl: webhook}},
+	}
+	start := server.clock.Now()
+	results, err := nexmo.Numbers.UpdateMany(updates)
+	if err == nil {
+		t.Error("Expected an error reporting the failed update")
+	}
+	if len(results) != len(updates) {
+		t.Fatalf("Got %d results, want %d", len(results), len(updates))
+	}
+	for i, result := range results {
+		if result.MSISDN != updates[i].MSISDN {
+			t.Errorf("Result %d is for %s, want %s", i, result.MSISDN, updates[i].MSISDN)
+		}
+		if wantErr := i == 1; (result.Err != nil) != wantErr {
+			t.Errorf("Update of %s failed with %v, want error: %v", result.MSISDN, result.Err, wantErr)
+		}
+	}
+	for _, msisdn := range []string{"14155550100", "12125551985"} {
+		if owned, err := nexmo.Numbers.Get(msisdn); err != nil || owned.MoHttpUrl != webhook {
+			t.Errorf("Updated number = %+v, %v, want moHttpUrl %s", owned, err, webhook)
+		}
+	}
+
+	// Concurrent requests may reach the server out of order, so only check
+	// that the updates took as long as spacing them out requires.
+	if elapsed := server.clock.Now().Sub(start); elapsed < 2*numbersInterval {
+		t.Errorf("3 updates took %v, want at least %v", elapsed, 2*numbersInterval)
+	}
+}
+
+func TestBuyAndConfirm(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+	server.provisionDelay = 12 * time.Second
+
+	start := server.clock.Now()
+	owned, err := nexmo.Numbers.BuyAndConfirm(context.Background(), "US", "14155550100", []string{FeatureSMS}, time.Minute)
+	if err != nil {
+		t.Fatal("BuyAndConfirm failed with error:", err)
+	}
+	if owned.MSISDN != "14155550100" || !HasFeature(*owned, FeatureSMS) {
+		t.Errorf("BuyAndConfirm = %+v, want the provisioned number", owned)
+	}
+	if waited := server.clock.Now().Sub(start); waited < server.provisionDelay {
+		t.Errorf("Confirmed after %v, before the number was provisioned", waited)
+	}
+
+	server.provisionDelay = time.Hour
+	if _, err := nexmo.Numbers.BuyAndConfirm(context.Background(), "US", "12125551985", nil, time.Minute); err != ErrNotProvisioned {
+		t.Errorf("BuyAndConfirm of a slow number = %v, want ErrNotProvisioned", err)
+	}
+	if _, err := nexmo.Numbers.BuyAndConfirm(context.Background(), "US", "19995550100", nil, time.Minute); err == nil {
+		t.Error("Expected an error buying a number that isn't for sale")
+	}
+
+	server.checkSpacing()
+
+	// A number listed without the expected features isn't confirmed.
+	nexmo, _ = newNumbersServer(t)
+	if _, err := nexmo.Numbers.BuyAndConfirm(context.Background(), "US", "14155550100", []string{FeatureVoice}, time.Minute); err != ErrNotProvisioned {
+		t.Errorf("BuyAndConfirm of an SMS only number for voice = %v, want ErrNotProvisioned", err)
+	}
+}
+
+func TestLinkApplication(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+
+	numToBuy := "14155550100"
+	if _, err := nexmo.Numbers.BuyPhoneNumber("US", numToBuy); err != nil {
+		t.Fatal("Error when buying phone number:", err)
+	}
+
+	err := nexmo.Numbers.LinkApplication("US", numToBuy, "no-such-app")
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) {
+		t.Errorf("Linking to an unknown application = %v, want a *RequestError", err)
+	}
+	if err := nexmo.Numbers.LinkApplication("US", numToBuy, ""); err == nil {
+		t.Error("Expected error with a blank application ID")
+	}
+
+	if err := nexmo.Numbers.LinkApplication("US", numToBuy, testAppID); err != nil {
+		t.Error("Error when linking application:", err)
+	}
+	if owned, err := nexmo.Numbers.Get(numToBuy); err != nil || owned.AppID != testAppID {
+		t.Errorf("Linked number = %+v, %v, want app_id %s", owned, err, testAppID)
+	}
+
+	if err := nexmo.Numbers.UnlinkApplication("US", numToBuy); err != nil {
+		t.Error("Error when unlinking application:", err)
+	}
+	if owned, err := nexmo.Numbers.Get(numToBuy); err != nil || owned.AppID != "" {
+		t.Errorf("Unlinked number = %+v, %v, want no app_id", owned, err)
+	}
+
+	server.checkSpacing()
+}
+
+var hasFeatureTests = []struct {
+	features []string
+	feature  string
+	want     bool
+}{
+	{[]string{"SMS", "VOICE"}, FeatureVoice, true},
+	{[]string{"SMS", "VOICE"}, FeatureMMS, false},
+	{[]string{"sms"}, FeatureSMS, true},
+	{nil, FeatureSMS, false},
+}
+
+func TestHasFeature(t *testing.T) {
+	for _, test := range hasFeatureTests {
+		n := OwnedNumber{Features: test.features}
+		if got := HasFeature(n, test.feature); got != test.want {
+			t.Errorf("HasFeature(%v, %s) = %v, want %v", test.features, test.feature, got, test.want)
+		}
+	}
 }
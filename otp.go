@@ -0,0 +1,77 @@
+package nexmo
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Defaults for OTPOptions.
+const (
+	DefaultOTPTemplate = "Your verification code is {code}"
+	DefaultOTPTTL      = 5 * time.Minute
+)
+
+// OTPOptions customizes a one-time password sent with SendOTP.
+type OTPOptions struct {
+	// From is the sender. If empty, SMS.SenderConfig applies as for Send.
+	From string
+
+	// Template is the message text, with {code} replaced by the code.
+	// Defaults to DefaultOTPTemplate.
+	Template string
+
+	// TTL is how long Nexmo tries to deliver the code for, after which it
+	// is no use anyway. Defaults to DefaultOTPTTL, and must be between
+	// MinTTL and MaxTTL.
+	TTL time.Duration
+
+	// Flash shows the code on the screen without storing it in the inbox.
+	Flash bool
+
+	// ClientReference is sent as the message's client-ref, e.g. to match
+	// the delivery receipt to the login attempt.
+	ClientReference string
+}
+
+// SendOTP sends code to to as a one-time password: a text message with a
+// short TTL that requests a delivery receipt. It returns the ID of the
+// message, or an error if it wasn't sent or Nexmo rejected it.
+func (c *SMS) SendOTP(to, code string, opts OTPOptions) (string, error) {
+	if len(code) <= 0 {
+		return "", errors.New("Invalid code specified")
+	}
+	template := opts.Template
+	if template == "" {
+		template = DefaultOTPTemplate
+	}
+	if !strings.Contains(template, "{code}") {
+		return "", errors.New("OTP template has no {code} placeholder")
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultOTPTTL
+	}
+
+	msg := &SMSMessage{
+		From:                opts.From,
+		To:                  to,
+		Type:                Text,
+		Text:                strings.Replace(template, "{code}", code, -1),
+		RequestStatusReport: true,
+		ClientReference:     opts.ClientReference,
+	}
+	if err := msg.SetTTL(ttl); err != nil {
+		return "", err
+	}
+	if opts.Flash {
+		msg.Class = Flash
+	}
+
+	resp, err := c.Send(msg)
+	result := newSendResult(to, resp, err)
+	if result.Err != nil {
+		return "", result.Err
+	}
+	return result.MessageID, nil
+}
@@ -0,0 +1,56 @@
+package nexmo
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSendOTP(t *testing.T) {
+	var form url.Values
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		if form.Get("to") == "447700900002" {
+			w.Write([]byte(`{"message-count": "1", "messages": [{"status": "7", "error-text": "Number barred"}]}`))
+			return
+		}
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+
+	id, err := nexmo.SMS.SendOTP("447700900000", "123456", OTPOptions{From: "gonexmo", Flash: true, ClientReference: "login-42"})
+	if err != nil || id != "0A00000001" {
+		t.Fatalf("SendOTP = %q, %v, want the message ID", id, err)
+	}
+	want := map[string]string{
+		"text":              "Your verification code is 123456",
+		"ttl":               "300000",
+		"status-report-req": "1",
+		"message-class":     "0",
+		"client-ref":        "login-42",
+	}
+	for name, value := range want {
+		if got := form.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+
+	opts := OTPOptions{From: "gonexmo", Template: "{code} is your gonexmo code", TTL: time.Minute}
+	if _, err := nexmo.SMS.SendOTP("447700900000", "1234", opts); err != nil {
+		t.Fatal("SendOTP with a template failed with error:", err)
+	}
+	if form.Get("text") != "1234 is your gonexmo code" || form.Get("ttl") != "60000" || form.Get("message-class") != "" {
+		t.Errorf("Unexpected form for a custom OTP: %v", form)
+	}
+
+	if _, err := nexmo.SMS.SendOTP("447700900002", "1234", OTPOptions{From: "gonexmo"}); err == nil {
+		t.Error("Expected an error when Nexmo rejects the message")
+	}
+	if _, err := nexmo.SMS.SendOTP("447700900000", "1234", OTPOptions{From: "gonexmo", Template: "No code"}); err == nil {
+		t.Error("Expected an error for a template without {code}")
+	}
+	if _, err := nexmo.SMS.SendOTP("447700900000", "1234", OTPOptions{From: "gonexmo", TTL: time.Second}); err == nil {
+		t.Error("Expected an error for a TTL below MinTTL")
+	}
+}
@@ -0,0 +1,124 @@
+package nexmo
+
+import "context"
+
+// maxNumbersPageSize is the largest page the number management API returns.
+const maxNumbersPageSize = 100
+
+// SearchAllAvailable returns every phone number available in a country that
+// matches opts, fetching as many pages as needed. opts.Size sets the page
+// size, defaulting to the maximum of 100, and opts.Index the first page.
+func (c *Numbers) SearchAllAvailable(ctx context.Context, countryCode string, opts NumberSearchOptions) ([]AvailableNumber, error) {
+	numbers := []AvailableNumber{}
+	err := c.eachAvailable(ctx, countryCode, opts, func(n AvailableNumber) bool {
+		numbers = append(numbers, n)
+		return true
+	})
+	return numbers, err
+}
+
+// ListAll returns every phone number owned by the account that matches opts,
+// fetching as many pages as needed. opts.Size sets the page size, defaulting
+// to the maximum of 100, and opts.Index the first page.
+func (c *Numbers) ListAll(ctx context.Context, opts NumberSearchOptions) ([]OwnedNumber, error) {
+	numbers := []OwnedNumber{}
+	err := c.eachOwned(ctx, opts, func(n OwnedNumber) bool {
+		numbers = append(numbers, n)
+		return true
+	})
+	return numbers, err
+}
+
+// AllInboundMessages returns every message the account received on msisdn
+// that matches opts, following the Reports API cursor from opts.Cursor.
+func (c *Numbers) AllInboundMessages(ctx context.Context, msisdn string, opts InboundOptions) ([]*RecvdMessage, error) {
+	messages := []*RecvdMessage{}
+	err := c.eachInbound(ctx, msisdn, opts, func(m *RecvdMessage) bool {
+		messages = append(messages, m)
+		return true
+	})
+	return messages, err
+}
+
+// eachAvailable calls fn with every available number matching opts, page by
+// page, until fn returns false. Pages are fetched until enough numbers under
+// MaxCost are found or the inventory is exhausted.
+func (c *Numbers) eachAvailable(ctx context.Context, countryCode string, opts NumberSearchOptions, fn func(AvailableNumber) bool) error {
+	return eachNumbersPage(ctx, opts, func(opts NumberSearchOptions) (int, int64, bool, error) {
+		response, err := c.searchAvailable(ctx, countryCode, opts)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		for _, n := range opts.affordable(response.Numbers) {
+			if !fn(n) {
+				return 0, 0, false, nil
+			}
+		}
+		return len(response.Numbers), response.Count, true, nil
+	})
+}
+
+// eachOwned calls fn with every owned number matching opts, page by page,
+// until fn returns false.
+func (c *Numbers) eachOwned(ctx context.Context, opts NumberSearchOptions, fn func(OwnedNumber) bool) error {
+	return eachNumbersPage(ctx, opts, func(opts NumberSearchOptions) (int, int64, bool, error) {
+		response, err := c.listOwned(ctx, opts)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		for _, n := range response.Numbers {
+			if !fn(n) {
+				return 0, 0, false, nil
+			}
+		}
+		return len(response.Numbers), response.Count, true, nil
+	})
+}
+
+// eachNumbersPage calls page for each page of a number management API
+// listing. page returns the number of results on the page, the total
+// number of results, and false to stop early.
+func eachNumbersPage(ctx context.Context, opts NumberSearchOptions, page func(NumberSearchOptions) (int, int64, bool, error)) error {
+	if opts.Size <= 0 {
+		opts.Size = maxNumbersPageSize
+	}
+	if opts.Index <= 0 {
+		opts.Index = 1
+	}
+	for seen := int64(0); ; opts.Index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, total, more, err := page(opts)
+		if err != nil || !more {
+			return err
+		}
+		seen += int64(n)
+		if n < opts.Size || seen >= total {
+			return nil
+		}
+	}
+}
+
+// eachInbound calls fn with every inbound message matching opts, page by
+// page, until fn returns false.
+func (c *Numbers) eachInbound(ctx context.Context, msisdn string, opts InboundOptions, fn func(*RecvdMessage) bool) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := c.InboundMessages(msisdn, opts)
+		if err != nil {
+			return err
+		}
+		for _, m := range page.Messages {
+			if !fn(m) {
+				return nil
+			}
+		}
+		if page.Next == "" {
+			return nil
+		}
+		opts.Cursor = page.Next
+	}
+}
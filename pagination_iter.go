@@ -0,0 +1,58 @@
+//go:build go1.23
+
+package nexmo
+
+import (
+	"context"
+	"iter"
+)
+
+// AllAvailable iterates over every phone number available in a country that
+// matches opts, fetching pages as the loop needs them. An error ends the
+// iteration after it is yielded. SearchAllAvailable returns the same numbers
+// as a slice.
+func (c *Numbers) AllAvailable(ctx context.Context, countryCode string, opts NumberSearchOptions) iter.Seq2[AvailableNumber, error] {
+	return func(yield func(AvailableNumber, error) bool) {
+		stopped := false
+		err := c.eachAvailable(ctx, countryCode, opts, func(n AvailableNumber) bool {
+			stopped = !yield(n, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(AvailableNumber{}, err)
+		}
+	}
+}
+
+// AllOwned iterates over every phone number owned by the account that
+// matches opts, fetching pages as the loop needs them. An error ends the
+// iteration after it is yielded. ListAll returns the same numbers as a slice.
+func (c *Numbers) AllOwned(ctx context.Context, opts NumberSearchOptions) iter.Seq2[OwnedNumber, error] {
+	return func(yield func(OwnedNumber, error) bool) {
+		stopped := false
+		err := c.eachOwned(ctx, opts, func(n OwnedNumber) bool {
+			stopped = !yield(n, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(OwnedNumber{}, err)
+		}
+	}
+}
+
+// AllInbound iterates over every message the account received on msisdn
+// that matches opts, fetching pages as the loop needs them. An error ends the
+// iteration after it is yielded. AllInboundMessages returns the same
+// messages as a slice.
+func (c *Numbers) AllInbound(ctx context.Context, msisdn string, opts InboundOptions) iter.Seq2[*RecvdMessage, error] {
+	return func(yield func(*RecvdMessage, error) bool) {
+		stopped := false
+		err := c.eachInbound(ctx, msisdn, opts, func(m *RecvdMessage) bool {
+			stopped = !yield(m, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package nexmo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAllAvailable(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+	server.addAvailable(23)
+
+	count := 0
+	for n, err := range nexmo.Numbers.AllAvailable(context.Background(), "US", NumberSearchOptions{Size: 10}) {
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if n.MSISDN == "" {
+			t.Error("Available number should have MSISDN set")
+		}
+		count++
+		if count == 12 {
+			break
+		}
+	}
+	if count != 12 {
+		t.Errorf("Iterated over %d numbers, want 12", count)
+	}
+	if len(server.requests) != 2 {
+		t.Errorf("Made %d requests, want only the 2 pages needed", len(server.requests))
+	}
+}
@@ -0,0 +1,91 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// addAvailable adds n numbers for sale in the US to the fake server, costing
+// 0.5, 1 and 1.5 in turn.
+func (s *numbersServer) addAvailable(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.available = append(s.available, AvailableNumber{
+			Country: "US",
+			MSISDN:  fmt.Sprintf("1202555%04d", i),
+			Type:    "mobile-lvn",
+			Cost:    0.5 + float64(i%3)*0.5,
+		})
+	}
+}
+
+func TestSearchAllAvailable(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+	server.addAvailable(23)
+
+	numbers, err := nexmo.Numbers.SearchAllAvailable(context.Background(), "US", NumberSearchOptions{Size: 10})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(numbers) != 25 {
+		t.Errorf("Got %d numbers, want 25", len(numbers))
+	}
+	if len(server.requests) != 3 {
+		t.Errorf("Made %d requests, want 3 pages", len(server.requests))
+	}
+	server.checkSpacing()
+
+	numbers, err = nexmo.Numbers.SearchAllAvailable(context.Background(), "FI", NumberSearchOptions{})
+	if err != nil || numbers == nil || len(numbers) != 0 {
+		t.Errorf("Search with no results = %v, %v, want an empty slice", numbers, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := nexmo.Numbers.SearchAllAvailable(ctx, "US", NumberSearchOptions{}); err != context.Canceled {
+		t.Errorf("Search with a cancelled context returned %v, want context.Canceled", err)
+	}
+}
+
+func TestSearchAllAvailableCancelledInPage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+	}))
+
+	if _, err := nexmo.Numbers.SearchAllAvailable(ctx, "US", NumberSearchOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Search cancelled during a request returned %v, want context.Canceled", err)
+	}
+}
+
+func TestSearchAvailableMaxCost(t *testing.T) {
+	nexmo, server := newNumbersServer(t)
+	server.addAvailable(23)
+
+	page, err := nexmo.Numbers.SearchAvailableWithOptions("US", NumberSearchOptions{MaxCost: 0.5})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, n := range page.Numbers {
+		if n.Cost > 0.5 {
+			t.Errorf("Number %s costs %v, more than MaxCost", n.MSISDN, n.Cost)
+		}
+	}
+
+	numbers, err := nexmo.Numbers.SearchAllAvailable(context.Background(), "US", NumberSearchOptions{Size: 10, MaxCost: 1})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	// 0.9 for each of the two fixed numbers, and 16 of the 23 added.
+	if len(numbers) != 18 {
+		t.Errorf("Got %d numbers costing at most 1, want 18", len(numbers))
+	}
+	if len(server.requests) != 4 {
+		t.Errorf("Made %d requests, want 1 + 3 pages", len(server.requests))
+	}
+}
@@ -0,0 +1,246 @@
+package nexmo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CountryPricing is the outbound SMS pricing for a single country.
+type CountryPricing struct {
+	CountryCode   string           `json:"countryCode"`
+	CountryName   string           `json:"countryName"`
+	Currency      string           `json:"currency"`
+	DefaultPrice  float64          `json:"defaultPrice,string"`
+	DialingPrefix string           `json:"dialingPrefix"`
+	Networks      []NetworkPricing `json:"networks"`
+}
+
+// NetworkPricing is the outbound SMS pricing for a single mobile network.
+type NetworkPricing struct {
+	Type        string  `json:"type"`
+	Price       float64 `json:"price,string"`
+	Currency    string  `json:"currency"`
+	MCC         string  `json:"mcc"`
+	MNC         string  `json:"mnc"`
+	NetworkCode string  `json:"networkCode"`
+	NetworkName string  `json:"networkName"`
+}
+
+// DefaultReferenceDataTTL is how long cached reference data is used for when
+// Client.ReferenceDataTTL is not set.
+const DefaultReferenceDataTTL = 24 * time.Hour
+
+// referenceData caches slow-changing data fetched from Nexmo, such as
+// pricing, for every service of a Client.
+type referenceData struct {
+	mu       sync.RWMutex
+	pricing  map[string]pricingEntry
+	currency string
+
+	// networks maps network codes to names from all the cached pricing,
+	// which was last fetched in full at fullPricingAt.
+	networks      map[string]string
+	fullPricingAt time.Time
+}
+
+type pricingEntry struct {
+	pricing   *CountryPricing
+	fetchedAt time.Time
+}
+
+func (c *Client) referenceDataTTL() time.Duration {
+	if c.ReferenceDataTTL > 0 {
+		return c.ReferenceDataTTL
+	}
+	return DefaultReferenceDataTTL
+}
+
+// cachedPricing returns the pricing for country, fetching it if it isn't
+// cached or has expired.
+func (c *Client) cachedPricing(ctx context.Context, country string) (*CountryPricing, error) {
+	c.refData.mu.RLock()
+	entry, ok := c.refData.pricing[country]
+	c.refData.mu.RUnlock()
+	if ok && c.clock.Now().Sub(entry.fetchedAt) < c.referenceDataTTL() {
+		return entry.pricing, nil
+	}
+
+	values := url.Values{}
+	values.Set("country", country)
+	var pricing *CountryPricing
+	if err := c.getPricing(ctx, "/account/get-pricing/outbound/sms", values, &pricing); err != nil {
+		return nil, err
+	}
+	if pricing.CountryCode == "" {
+		return nil, fmt.Errorf("No pricing found for country %s", country)
+	}
+
+	c.refData.mu.Lock()
+	if c.refData.pricing == nil {
+		c.refData.pricing = make(map[string]pricingEntry)
+	}
+	c.refData.pricing[country] = pricingEntry{pricing, c.clock.Now()}
+	if c.refData.networks == nil {
+		c.refData.networks = make(map[string]string)
+	}
+	addNetworkNames(c.refData.networks, pricing)
+	if pricing.Currency != "" {
+		c.refData.currency = pricing.Currency
+	}
+	c.refData.mu.Unlock()
+	return pricing, nil
+}
+
+// RefreshReferenceData fetches pricing for every country and replaces the
+// cached reference data shared by the Client's services.
+func (c *Client) RefreshReferenceData(ctx context.Context) error {
+	var fullPricing struct {
+		Countries []*CountryPricing `json:"countries"`
+	}
+	if err := c.getPricing(ctx, "/account/get-full-pricing/outbound/sms", url.Values{}, &fullPricing); err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	pricing := make(map[string]pricingEntry, len(fullPricing.Countries))
+	networks := make(map[string]string)
+	var currency string
+	for _, country := range fullPricing.Countries {
+		pricing[country.CountryCode] = pricingEntry{country, now}
+		addNetworkNames(networks, country)
+		if country.Currency != "" {
+			currency = country.Currency
+		}
+	}
+
+	c.refData.mu.Lock()
+	c.refData.pricing = pricing
+	c.refData.networks = networks
+	c.refData.fullPricingAt = now
+	if currency != "" {
+		c.refData.currency = currency
+	}
+	c.refData.mu.Unlock()
+	return nil
+}
+
+func addNetworkNames(networks map[string]string, pricing *CountryPricing) {
+	for _, network := range pricing.Networks {
+		if network.NetworkCode != "" && network.NetworkName != "" {
+			networks[network.NetworkCode] = network.NetworkName
+		}
+	}
+}
+
+// networkName returns the name of the network with code from the cached
+// pricing. Unless the full pricing was fetched within ReferenceDataTTL, an
+// unknown code refreshes the reference data first.
+func (c *Client) networkName(ctx context.Context, code string) (string, error) {
+	c.refData.mu.RLock()
+	name, ok := c.refData.networks[code]
+	fresh := c.clock.Now().Sub(c.refData.fullPricingAt) < c.referenceDataTTL()
+	c.refData.mu.RUnlock()
+	if ok {
+		return name, nil
+	}
+	if !fresh {
+		if err := c.RefreshReferenceData(ctx); err != nil {
+			return "", err
+		}
+		c.refData.mu.RLock()
+		name, ok = c.refData.networks[code]
+		c.refData.mu.RUnlock()
+		if ok {
+			return name, nil
+		}
+	}
+	return "", ErrUnknownNetwork
+}
+
+/*
+	GET /account/get-pricing/outbound/sms?api_key={api_key}&api_secret={api_secret}&country={country}
+	GET /account/get-full-pricing/outbound/sms?api_key={api_key}&api_secret={api_secret}
+*/
+
+func (c *Client) getPricing(ctx context.Context, path string, values url.Values, out interface{}) (err error) {
+	values.Set("api_key", c.apiKey)
+	values.Set("api_secret", c.apiSecret)
+
+	r, _ := http.NewRequest("GET", c.restURL+path+"?"+values.Encode(), nil)
+	r = r.WithContext(ctx)
+
+	defer c.wrapError(r, &err)
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	return json.Unmarshal(body, out)
+}
+
+// GetPricing retrieves the outbound SMS pricing for country, an ISO country
+// code such as "GB". Pricing is cached on the Client for ReferenceDataTTL.
+func (nexmo *Account) GetPricing(country string) (*CountryPricing, error) {
+	if len(country) <= 0 {
+		return nil, errors.New("Invalid country code field specified")
+	}
+	return nexmo.client.cachedPricing(context.Background(), country)
+}
+
+// ErrUnknownNetwork is returned by NetworkName for a network code that is
+// not in Nexmo's pricing.
+var ErrUnknownNetwork = errors.New("Unknown network code")
+
+// NetworkName returns the name of the mobile network with code, its MCC and
+// MNC as in MessageReport.Network and delivery receipts, e.g. "T-Mobile US"
+// for "310260". Names come from the pricing cached on the Client, which is
+// fetched in full for a code it doesn't have, at most once per
+// ReferenceDataTTL; Client.RefreshReferenceData refreshes it at any time.
+func (nexmo *Account) NetworkName(code string) (string, error) {
+	if len(code) <= 0 {
+		return "", errors.New("Invalid network code specified")
+	}
+	return nexmo.client.networkName(context.Background(), code)
+}
+
+// cachedCurrency returns the account currency from the cached pricing, or ""
+// if no pricing has been fetched.
+func (c *Client) cachedCurrency() string {
+	c.refData.mu.RLock()
+	defer c.refData.mu.RUnlock()
+	return c.refData.currency
+}
+
+// Currency returns the currency the account is billed in, such as "EUR". All
+// prices and balances returned by Nexmo are in this currency. It is looked up
+// once from the pricing data and cached on the Client, which then sets it as
+// MessageResponse.Currency of every send.
+func (nexmo *Account) Currency() (string, error) {
+	if currency := nexmo.client.cachedCurrency(); currency != "" {
+		return currency, nil
+	}
+
+	// Every country is priced in the account currency, so any will do.
+	pricing, err := nexmo.client.cachedPricing(context.Background(), "US")
+	if err != nil {
+		return "", err
+	}
+	if pricing.Currency == "" {
+		return "", errors.New("Pricing did not include a currency")
+	}
+	return pricing.Currency, nil
+}
@@ -0,0 +1,332 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to a rate limited send when the SMS
+// queue is full.
+type OverflowPolicy int
+
+const (
+	// QueueBlock makes Send wait until there is room in the queue.
+	QueueBlock OverflowPolicy = iota
+
+	// QueueReject makes Send fail immediately with ErrQueueFull.
+	QueueReject
+)
+
+// ErrThrottled is returned by Send when Nexmo rejects the request with HTTP
+// 429 Too Many Requests.
+var ErrThrottled = errors.New("Throttled by Nexmo")
+
+// ErrQueueFull is returned by Send when the rate limit queue is full and
+// QueueOverflow is QueueReject.
+var ErrQueueFull = errors.New("SMS send queue is full")
+
+// ErrQueueClosed is returned by Send for a rate limited message after Close.
+var ErrQueueClosed = errors.New("SMS send queue is closed")
+
+// maxQueuedInFlight is the most rate limited sends in flight at once. The
+// queue waits for one to complete before sending more, so slow responses
+// can't pile up goroutines.
+const maxQueuedInFlight = 32
+
+type sendResult struct {
+	response *MessageResponse
+	err      error
+}
+
+type queuedSend struct {
+	ctx  context.Context
+	msg  *SMSMessage
+	opts SendOptions
+	done chan sendResult
+}
+
+// enqueue hands msg to the rate limiting worker and waits for it to be sent.
+func (c *SMS) enqueue(ctx context.Context, msg *SMSMessage, opts SendOptions) (*MessageResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.startQueue()
+
+	// Hold off Close while handing the job over, so the queue isn't closed
+	// under us.
+	c.queueMutex.RLock()
+	if c.queueClosed {
+		c.queueMutex.RUnlock()
+		return nil, ErrQueueClosed
+	}
+	job := &queuedSend{ctx: ctx, msg: msg, opts: opts, done: make(chan sendResult, 1)}
+	c.pending.add()
+	if c.QueueOverflow == QueueReject {
+		select {
+		case c.queue <- job:
+		default:
+			c.queueMutex.RUnlock()
+			c.pending.done()
+			return nil, ErrQueueFull
+		}
+	} else {
+		select {
+		case c.queue <- job:
+		case <-ctx.Done():
+			c.queueMutex.RUnlock()
+			c.pending.done()
+			return nil, ctx.Err()
+		}
+	}
+	c.queueMutex.RUnlock()
+
+	select {
+	case result := <-job.done:
+		return result.response, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *SMS) startQueue() {
+	c.queueOnce.Do(func() {
+		c.queue = make(chan *queuedSend, c.QueueSize)
+		c.flushNow = make(chan struct{}, 1)
+		c.queueDone = make(chan struct{})
+		c.inFlight = make(chan struct{}, maxQueuedInFlight)
+		go c.drainQueue()
+	})
+}
+
+// drainQueue sends queued messages no faster than RateLimit allows, or at
+// once while Flush is running, until the queue is closed. Each send runs in
+// its own goroutine, up to maxQueuedInFlight at once, so slow responses
+// don't lower the send rate. Jobs whose context is done by their turn are
+// failed without taking up a slot.
+func (c *SMS) drainQueue() {
+	defer close(c.queueDone)
+
+	var next time.Time
+	for job := range c.queue {
+		if c.skipCancelled(job) {
+			continue
+		}
+		interval := time.Duration(float64(time.Second) / c.currentRate())
+		now := c.client.clock.Now()
+		if now.Before(next) && atomic.LoadInt32(&c.flushing) == 0 {
+			select {
+			case <-c.client.clock.After(next.Sub(now)):
+				now = next
+			case <-c.flushNow:
+			}
+		}
+		if c.skipCancelled(job) {
+			continue
+		}
+		next = now.Add(interval)
+
+		c.inFlight <- struct{}{}
+		go func(job *queuedSend) {
+			defer c.pending.done()
+			response, err := c.send(job.ctx, job.msg, job.opts)
+			<-c.inFlight
+			c.observeSend(response, err)
+			job.done <- sendResult{response, err}
+		}(job)
+	}
+}
+
+// skipCancelled fails job if its context is done, and reports whether it did.
+func (c *SMS) skipCancelled(job *queuedSend) bool {
+	err := job.ctx.Err()
+	if err == nil {
+		return false
+	}
+	job.done <- sendResult{nil, err}
+	c.pending.done()
+	return true
+}
+
+// Close stops the rate limit queue: later rate limited sends fail with
+// ErrQueueClosed, and the messages already queued are sent at the normal
+// rate. It returns once they and every send in flight have completed and
+// the queue's worker has exited. Use Flush first to send them at once.
+func (c *SMS) Close() {
+	c.startQueue()
+	c.queueMutex.Lock()
+	if !c.queueClosed {
+		c.queueClosed = true
+		close(c.queue)
+	}
+	c.queueMutex.Unlock()
+
+	<-c.queueDone
+	<-c.pending.wait()
+}
+
+// Flush sends every message waiting in the rate limit queue immediately,
+// ignoring RateLimit, and waits until they and any sends in flight have
+// completed, or until ctx is done. Use it on shutdown so queued messages
+// aren't dropped.
+func (c *SMS) Flush(ctx context.Context) error {
+	c.startQueue()
+	atomic.AddInt32(&c.flushing, 1)
+	defer atomic.AddInt32(&c.flushing, -1)
+
+	select {
+	case c.flushNow <- struct{}{}:
+	default:
+	}
+	defer func() {
+		// Don't let a signal nobody took skip a later wait.
+		select {
+		case <-c.flushNow:
+		default:
+		}
+	}()
+
+	select {
+	case <-c.pending.wait():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain waits until every message in the rate limit queue has been sent at
+// the normal rate and every send in flight has completed.
+func (c *SMS) Drain() {
+	<-c.pending.wait()
+}
+
+// pendingSends counts the rate limited sends that are queued or in flight.
+type pendingSends struct {
+	mu    sync.Mutex
+	count int
+	idle  chan struct{}
+}
+
+func (p *pendingSends) add() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.count == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.count++
+}
+
+func (p *pendingSends) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count--
+	if p.count == 0 {
+		close(p.idle)
+	}
+}
+
+func (c *SMS) pendingCount() int {
+	c.pending.mu.Lock()
+	defer c.pending.mu.Unlock()
+	return c.pending.count
+}
+
+// wait returns a channel that is closed once there are no pending sends.
+func (p *pendingSends) wait() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.count == 0 {
+		idle := make(chan struct{})
+		close(idle)
+		return idle
+	}
+	return p.idle
+}
+
+// waitForCountry blocks until a message may be sent to the number to under
+// CountryRateLimits. Each caller reserves the next free slot for the country
+// before waiting, so concurrent sends are spaced out rather than released
+// together.
+func (c *SMS) waitForCountry(ctx context.Context, to string) error {
+	country := countryForMSISDN(to)
+	limit := c.CountryRateLimits[country]
+	if limit <= 0 {
+		return nil
+	}
+
+	c.rateMutex.Lock()
+	now := c.client.clock.Now()
+	slot := c.countryNext[country]
+	if slot.Before(now) {
+		slot = now
+	}
+	if c.countryNext == nil {
+		c.countryNext = make(map[string]time.Time)
+	}
+	c.countryNext[country] = slot.Add(time.Duration(float64(time.Second) / limit))
+	c.rateMutex.Unlock()
+
+	if !slot.After(now) {
+		return nil
+	}
+	select {
+	case <-c.client.clock.After(slot.Sub(now)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Adaptive rate limiting multiplies the rate by adaptiveDecrease whenever a
+// send is throttled, and adds back adaptiveIncrease of RateLimit for each
+// successful send. The rate never drops below adaptiveMinimum of RateLimit.
+const (
+	adaptiveDecrease = 0.5
+	adaptiveIncrease = 0.05
+	adaptiveMinimum  = 0.02
+)
+
+// currentRate returns the number of messages per second to send at.
+func (c *SMS) currentRate() float64 {
+	if !c.AdaptiveRateLimit {
+		return c.RateLimit
+	}
+
+	c.rateMutex.Lock()
+	defer c.rateMutex.Unlock()
+	if c.adaptiveRate == 0 {
+		c.adaptiveRate = c.RateLimit
+	}
+	return c.adaptiveRate
+}
+
+// observeSend adjusts the adaptive rate after a send.
+func (c *SMS) observeSend(resp *MessageResponse, err error) {
+	if !c.AdaptiveRateLimit {
+		return
+	}
+
+	throttled := errors.Is(err, ErrThrottled)
+	if resp != nil {
+		for _, report := range resp.Messages {
+			if report.Status == ResponseThrottled {
+				throttled = true
+			}
+		}
+	}
+
+	c.rateMutex.Lock()
+	defer c.rateMutex.Unlock()
+	if c.adaptiveRate == 0 {
+		c.adaptiveRate = c.RateLimit
+	}
+	switch {
+	case throttled:
+		c.adaptiveRate = math.Max(c.adaptiveRate*adaptiveDecrease, c.RateLimit*adaptiveMinimum)
+	case err == nil:
+		c.adaptiveRate = math.Min(c.adaptiveRate+c.RateLimit*adaptiveIncrease, c.RateLimit)
+	}
+}
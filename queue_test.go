@@ -0,0 +1,214 @@
+package nexmo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveRate(t *testing.T) {
+	c := &SMS{RateLimit: 10, AdaptiveRateLimit: true}
+	throttled := &MessageResponse{Messages: []MessageReport{{Status: ResponseThrottled}}}
+	sent := &MessageResponse{Messages: []MessageReport{{Status: ResponseSuccess}}}
+
+	if rate := c.currentRate(); rate != 10 {
+		t.Errorf("Initial rate = %v, want 10", rate)
+	}
+
+	c.observeSend(throttled, nil)
+	if rate := c.currentRate(); rate != 5 {
+		t.Errorf("Rate after throttled response = %v, want 5", rate)
+	}
+
+	c.observeSend(nil, ErrThrottled)
+	if rate := c.currentRate(); rate != 2.5 {
+		t.Errorf("Rate after HTTP 429 = %v, want 2.5", rate)
+	}
+
+	c.observeSend(sent, nil)
+	if rate := c.currentRate(); rate != 3 {
+		t.Errorf("Rate after success = %v, want 3", rate)
+	}
+
+	for i := 0; i < 100; i++ {
+		c.observeSend(throttled, nil)
+	}
+	if rate := c.currentRate(); rate != 0.2 {
+		t.Errorf("Rate after repeated throttling = %v, want minimum 0.2", rate)
+	}
+
+	for i := 0; i < 100; i++ {
+		c.observeSend(sent, nil)
+	}
+	if rate := c.currentRate(); rate != 10 {
+		t.Errorf("Rate after repeated success = %v, want RateLimit 10", rate)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var mu sync.Mutex
+	sent := 0
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent++
+		mu.Unlock()
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	// A real clock, so that the rate limiter really waits between sends.
+	nexmo.clock = realClock{}
+	nexmo.SMS.RateLimit = 0.001
+	nexmo.SMS.QueueSize = 10
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+			if _, err := nexmo.SMS.Send(msg); err != nil {
+				t.Error("Send failed with error:", err)
+			}
+		}()
+	}
+
+	// Wait for the first message to be sent and the other two to be held
+	// back by the rate limiter.
+	for {
+		mu.Lock()
+		n := sent
+		mu.Unlock()
+		if n == 1 && nexmo.SMS.pendingCount() == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := nexmo.SMS.Flush(ctx); err != nil {
+		t.Fatal("Flush failed with error:", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if sent != 3 {
+		t.Errorf("%d messages were sent after Flush, want 3", sent)
+	}
+	wg.Wait()
+	nexmo.SMS.Drain()
+}
+
+func TestQueueClose(t *testing.T) {
+	var mu sync.Mutex
+	var sent []time.Time
+	var clk *fakeClock
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, clk.Now())
+		mu.Unlock()
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.RateLimit = 2
+
+	for i := 0; i < 3; i++ {
+		if _, err := nexmo.SMS.Send(&SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}); err != nil {
+			t.Fatal("Send failed with error:", err)
+		}
+	}
+	for i := 1; i < len(sent); i++ {
+		if gap := sent[i].Sub(sent[i-1]); gap < 500*time.Millisecond {
+			t.Errorf("Messages %d and %d were %v apart, want at least 500ms", i-1, i, gap)
+		}
+	}
+
+	nexmo.SMS.Close()
+	select {
+	case <-nexmo.SMS.queueDone:
+	default:
+		t.Error("Queue worker still running after Close")
+	}
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if _, err := nexmo.SMS.Send(msg); err != ErrQueueClosed {
+		t.Errorf("Send after Close = %v, want ErrQueueClosed", err)
+	}
+	nexmo.SMS.Close()
+}
+
+func TestQueueSkipsCancelled(t *testing.T) {
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.RateLimit = 1
+	defer nexmo.SMS.Close()
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if _, err := nexmo.SMS.Send(msg); err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+
+	// Queue a job whose context is cancelled while it waits.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job := &queuedSend{ctx: ctx, msg: &SMSMessage{}, done: make(chan sendResult, 1)}
+	nexmo.SMS.pending.add()
+	nexmo.SMS.queue <- job
+
+	start := clk.Now()
+	msg = &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if _, err := nexmo.SMS.Send(msg); err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	if result := <-job.done; result.err != context.Canceled {
+		t.Errorf("Cancelled job got %v, want context.Canceled", result.err)
+	}
+	if waited := clk.Now().Sub(start); waited != time.Second {
+		t.Errorf("Send after a cancelled job waited %v, want one interval of 1s", waited)
+	}
+}
+
+func TestCountryRateLimits(t *testing.T) {
+	var mu sync.Mutex
+	sent := map[string][]time.Time{}
+	var clk *fakeClock
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		to := r.PostForm.Get("to")
+		sent[to] = append(sent[to], clk.Now())
+		mu.Unlock()
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+	nexmo.SMS.CountryRateLimits = map[string]float64{"IN": 2}
+
+	for i := 0; i < 3; i++ {
+		for _, to := range []string{"919876543210", "14155550100"} {
+			start := clk.Now()
+			if _, err := nexmo.SMS.Send(&SMSMessage{From: "gonexmo", To: to, Type: Text, Text: "Hello"}); err != nil {
+				t.Fatal("Send failed with error:", err)
+			}
+			if to == "14155550100" && clk.Now() != start {
+				t.Errorf("Send to an unlimited country waited %v", clk.Now().Sub(start))
+			}
+		}
+	}
+
+	start := clk.Now()
+	msg := &SMSMessage{From: "gonexmo", To: "919876543210", Type: Text, Text: "Hello"}
+	if _, err := nexmo.SMS.SendContextWithOptions(context.Background(), msg, SendOptions{BypassRateLimit: true}); err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	if waited := clk.Now().Sub(start); waited == 0 {
+		t.Error("Send bypassing the rate limit did not wait for the country's limit")
+	}
+
+	india := sent["919876543210"]
+	if len(india) != 4 {
+		t.Fatalf("Sent %d messages to India, want 4", len(india))
+	}
+	for i := 1; i < len(india); i++ {
+		if gap := india[i].Sub(india[i-1]); gap < 500*time.Millisecond {
+			t.Errorf("Messages %d and %d to India were %v apart, want at least 500ms", i-1, i, gap)
+		}
+	}
+}
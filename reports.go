@@ -0,0 +1,191 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Reports represents the Reports API functions for querying the records of
+// messages sent and received by the account.
+type Reports struct {
+	client *Client
+}
+
+// DeliveryStatusRecord is the Reports API record of a single SMS.
+type DeliveryStatusRecord struct {
+	MessageID            string    `json:"message_id"`
+	ClientReference      string    `json:"client_ref"`
+	Direction            string    `json:"direction"`
+	From                 string    `json:"from"`
+	To                   string    `json:"to"`
+	Network              string    `json:"network"`
+	NetworkName          string    `json:"network_name"`
+	Country              string    `json:"country"`
+	CountryName          string    `json:"country_name"`
+	DateReceived         NexmoTime `json:"date_received"`
+	DateFinalized        NexmoTime `json:"date_finalized"`
+	Latency              string    `json:"latency"`
+	Status               string    `json:"status"`
+	ErrorCode            string    `json:"error_code"`
+	ErrorCodeDescription string    `json:"error_code_description"`
+	Currency             string    `json:"currency"`
+	TotalPrice           string    `json:"total_price"`
+	MessageBody          string    `json:"message_body"`
+}
+
+type recordsResponse struct {
+	RequestStatus string                 `json:"request_status"`
+	ErrorTitle    string                 `json:"title"`
+	ErrorDetail   string                 `json:"detail"`
+	Records       []DeliveryStatusRecord `json:"records"`
+	Links         struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+}
+
+// nextCursor returns the cursor for the next page of records, or "" on the
+// last page.
+func (r *recordsResponse) nextCursor() string {
+	next, err := url.Parse(r.Links.Next.Href)
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("cursor")
+}
+
+/*
+	GET https://api.nexmo.com/v2/reports/records?account_id={api_key}&product=SMS&direction={direction}&id={message_id}
+*/
+
+// MessageStatus looks up the record of the outbound SMS messageID, e.g. to
+// reconcile a delivery receipt that never arrived. Records lag behind sends,
+// so a message sent in the last few minutes may not be found yet, or may not
+// have its final status.
+func (c *Reports) MessageStatus(messageID string) (*DeliveryStatusRecord, error) {
+	if len(messageID) <= 0 {
+		return nil, errors.New("Invalid message ID specified")
+	}
+
+	values := url.Values{}
+	values.Set("direction", "outbound")
+	values.Set("id", messageID)
+
+	response, err := c.records(values)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Records) == 0 {
+		return nil, fmt.Errorf("No record found for message %s", messageID)
+	}
+	return &response.Records[0], nil
+}
+
+// ReportSummary totals the SMS messages of one direction sent or received in
+// a time window.
+type ReportSummary struct {
+	Direction    string
+	From, To     time.Time
+	MessageCount int
+	TotalCost    Money
+}
+
+// summaryPageSize is the largest page the Reports API returns.
+const summaryPageSize = 1000
+
+/*
+	GET https://api.nexmo.com/v2/reports/records?account_id={api_key}&product=SMS&direction={direction}&date_start={from}&date_end={to}&page_size=1000
+*/
+
+// Summary counts the SMS messages in direction, "outbound" or "inbound",
+// between from and to, and totals their cost. The Reports API has no
+// aggregate query, so this reads every record in the window, a thousand per
+// request; keep windows small on busy accounts. Records lag behind sends, so
+// the last few minutes may be incomplete.
+func (c *Reports) Summary(from, to time.Time, direction string) (*ReportSummary, error) {
+	if direction != "outbound" && direction != "inbound" {
+		return nil, fmt.Errorf("Invalid direction %q specified", direction)
+	}
+	if from.IsZero() || !to.After(from) {
+		return nil, errors.New("Invalid time window specified")
+	}
+
+	summary := &ReportSummary{Direction: direction, From: from, To: to}
+	cursor := ""
+	for {
+		values := url.Values{}
+		values.Set("direction", direction)
+		values.Set("date_start", from.UTC().Format(time.RFC3339))
+		values.Set("date_end", to.UTC().Format(time.RFC3339))
+		values.Set("page_size", strconv.Itoa(summaryPageSize))
+		if cursor != "" {
+			values.Set("cursor", cursor)
+		}
+
+		response, err := c.records(values)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range response.Records {
+			summary.MessageCount++
+			if record.TotalPrice == "" {
+				continue
+			}
+			price, err := strconv.ParseFloat(record.TotalPrice, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid price %q for message %s", record.TotalPrice, record.MessageID)
+			}
+			if summary.TotalCost.Currency == "" {
+				summary.TotalCost.Currency = record.Currency
+			} else if record.Currency != summary.TotalCost.Currency {
+				return nil, fmt.Errorf("Can not total prices in both %s and %s",
+					summary.TotalCost.Currency, record.Currency)
+			}
+			summary.TotalCost.Amount += price
+		}
+
+		if cursor = response.nextCursor(); cursor == "" {
+			return summary, nil
+		}
+	}
+}
+
+// records queries SMS records matching values.
+func (c *Reports) records(values url.Values) (response *recordsResponse, err error) {
+	values.Set("account_id", c.client.apiKey)
+	values.Set("product", "SMS")
+
+	r, _ := http.NewRequest("GET", c.client.apiURL+"/v2/reports/records?"+values.Encode(), nil)
+	r.SetBasicAuth(c.client.apiKey, c.client.apiSecret)
+
+	defer c.client.wrapError(r, &err)
+	resp, err := c.client.do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Reports request failed: %s %s",
+			response.ErrorTitle, response.ErrorDetail)
+	}
+	for i := range response.Records {
+		record := &response.Records[i]
+		record.DateReceived = c.client.localTime(record.DateReceived)
+		record.DateFinalized = c.client.localTime(record.DateFinalized)
+	}
+	return response, nil
+}
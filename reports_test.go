@@ -0,0 +1,79 @@
+package nexmo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestReportsSummary(t *testing.T) {
+	var requests int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		if query.Get("direction") != "outbound" || query.Get("date_start") != "2020-01-01T00:00:00Z" ||
+			query.Get("date_end") != "2020-01-02T00:00:00Z" {
+			t.Errorf("Unexpected query %v", query)
+		}
+		if query.Get("cursor") == "" {
+			w.Write([]byte(`{
+				"request_status": "SUCCESS",
+				"_links": {"next": {"href": "https://api.nexmo.com/v2/reports/records?cursor=abc123"}},
+				"records": [
+					{"message_id": "0A00000001", "currency": "EUR", "total_price": "0.03330000"},
+					{"message_id": "0A00000002", "currency": "EUR", "total_price": "0.06660000"}
+				]
+			}`))
+			return
+		}
+		w.Write([]byte(`{
+			"request_status": "SUCCESS",
+			"records": [
+				{"message_id": "0A00000003", "currency": "EUR", "total_price": "0.10000000"},
+				{"message_id": "0A00000004", "status": "rejected"}
+			]
+		}`))
+	}))
+
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	summary, err := nexmo.Reports.Summary(from, from.Add(24*time.Hour), "outbound")
+	if err != nil {
+		t.Fatal("Summary failed with error:", err)
+	}
+	if requests != 2 {
+		t.Errorf("Made %d requests, want one for each of the 2 pages", requests)
+	}
+	if summary.MessageCount != 4 || summary.TotalCost.Currency != "EUR" ||
+		summary.TotalCost.Amount < 0.19989 || summary.TotalCost.Amount > 0.19991 {
+		t.Errorf("Unexpected summary %+v, want 4 messages costing 0.1999 EUR", summary)
+	}
+
+	if _, err := nexmo.Reports.Summary(from, from.Add(time.Hour), "sideways"); err == nil {
+		t.Error("Expected an error for an invalid direction")
+	}
+	if _, err := nexmo.Reports.Summary(from, from, "inbound"); err == nil {
+		t.Error("Expected an error for an empty window")
+	}
+}
+
+func TestReportsLocation(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"request_status": "SUCCESS", "records": [{"message_id": "0A00000001",
+			"date_received": "2020-01-01T12:00:00Z", "date_finalized": "2020-01-01 12:00:05"}]}`))
+	}))
+	nexmo.Location = time.FixedZone("UTC+8", 8*60*60)
+
+	record, err := nexmo.Reports.MessageStatus("0A00000001")
+	if err != nil {
+		t.Fatal("MessageStatus failed with error:", err)
+	}
+	want := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	if !record.DateReceived.Equal(want) || record.DateReceived.Location() != nexmo.Location {
+		t.Errorf("DateReceived = %v, want %v in UTC+8", record.DateReceived, want)
+	}
+	// A time without a zone is read in Location.
+	wantFinalized := time.Date(2020, 1, 1, 12, 0, 5, 0, nexmo.Location)
+	if !record.DateFinalized.Equal(wantFinalized) || record.DateFinalized.Location() != nexmo.Location {
+		t.Errorf("DateFinalized = %v, want %v", record.DateFinalized, wantFinalized)
+	}
+}
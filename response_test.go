@@ -0,0 +1,67 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var validateResponseTests = []struct {
+	resp    MessageResponse
+	wantErr bool
+}{
+	{MessageResponse{MessageCount: 1, Messages: []MessageReport{{MessageID: "a"}}}, false},
+	{MessageResponse{MessageCount: 2, Messages: []MessageReport{{MessageID: "a"}, {MessageID: "b"}}}, false},
+	{MessageResponse{MessageCount: 2, Messages: []MessageReport{{MessageID: "a"}, {MessageID: "a"}}}, true},
+	{MessageResponse{MessageCount: 2, Messages: []MessageReport{{MessageID: "a"}}}, true},
+	{MessageResponse{MessageCount: 2, Messages: []MessageReport{{Status: ResponseThrottled}, {Status: ResponseThrottled}}}, false},
+}
+
+func TestMessageResponseValidate(t *testing.T) {
+	for i, test := range validateResponseTests {
+		err := test.resp.Validate()
+		if (err != nil) != test.wantErr {
+			t.Errorf("%d: Validate() = %v, want error: %v", i, err, test.wantErr)
+		}
+	}
+}
+
+var decodeResponseTests = []struct {
+	body       string
+	wantCount  int
+	wantStatus ResponseCode
+	wantErr    bool
+}{
+	{`{"message-count": "1", "messages": [{"status": "0"}]}`, 1, ResponseSuccess, false},
+	{`{"message-count": 1, "messages": [{"status": 0}]}`, 1, ResponseSuccess, false},
+	{`{"message-count": "1", "messages": [{"status": 7}]}`, 1, ResponseNumberBarred, false},
+	{`{"message-count": 2, "messages": [{"status": "1"}, {"status": "1"}]}`, 2, ResponseThrottled, false},
+	{`{"message-count": "one", "messages": []}`, 0, 0, true},
+	{`{"message-count": 1, "messages": [{"status": true}]}`, 0, 0, true},
+}
+
+func TestDecodeMessageResponse(t *testing.T) {
+	for _, test := range decodeResponseTests {
+		var resp MessageResponse
+		err := json.Unmarshal([]byte(test.body), &resp)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, want error: %v", test.body, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if resp.MessageCount != test.wantCount || resp.Messages[0].Status != test.wantStatus {
+			t.Errorf("Unmarshal(%s) = %d messages, status %v, want %d, %v",
+				test.body, resp.MessageCount, resp.Messages[0].Status, test.wantCount, test.wantStatus)
+		}
+	}
+
+	encoded, err := json.Marshal(MessageResponse{MessageCount: 1, Messages: []MessageReport{{Status: ResponseThrottled}}})
+	if err != nil {
+		t.Fatal("Marshal failed with error:", err)
+	}
+	var resp MessageResponse
+	if err := json.Unmarshal(encoded, &resp); err != nil || resp.Messages[0].Status != ResponseThrottled {
+		t.Errorf("Round trip of %s = %+v, %v", encoded, resp, err)
+	}
+}
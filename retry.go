@@ -0,0 +1,98 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Retryable is the default classification of a sent SMS as worth retrying:
+// Nexmo accepted the request but reported every part of the message as
+// throttled or failed by an internal error. Errors sending the request are
+// not retryable here, as send already retries those that are safe to.
+func Retryable(resp *MessageResponse, err error) bool {
+	if err != nil || resp == nil || len(resp.Messages) == 0 {
+		return false
+	}
+	for _, report := range resp.Messages {
+		if report.Status != ResponseThrottled && report.Status != ResponseInternalError {
+			return false
+		}
+	}
+	return true
+}
+
+// send sends a message that has already been validated by Send, retrying it
+// as Client.Backoff and Client.RetryPredicate, or their overrides in opts,
+// allow. Both failed requests and messages Nexmo failed are retried here,
+// rather than the request also being retried by Client.do, so every attempt
+// counts towards the same Backoff limit.
+func (c *SMS) send(ctx context.Context, msg *SMSMessage, opts SendOptions) (*MessageResponse, error) {
+	retryable := c.client.retryPredicate(opts)
+	backoff := c.client.backoff(opts)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.sendOnce(ctx, msg)
+		if backoff == nil || ctx.Err() != nil || !(requestRetryable(msg, err) || retryable(resp, err)) {
+			return resp, err
+		}
+
+		lastErr := err
+		if lastErr == nil {
+			lastErr = retryError(resp)
+		}
+		delay, ok := backoff.NextDelay(attempt, lastErr)
+		if !ok {
+			return resp, err
+		}
+		c.client.logf(ctx, "[%s] Retrying message in %v: %v", msg.correlationID, delay, lastErr)
+
+		select {
+		case <-c.client.clock.After(delay):
+		case <-ctx.Done():
+			return resp, err
+		}
+	}
+}
+
+// serverError is the error of a send Nexmo answered with a 5xx status. The
+// message may have been accepted anyway.
+type serverError struct {
+	statusCode int
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("Unexpected response status %d", e.statusCode)
+}
+
+// requestRetryable reports whether err, from the request sending msg, is
+// worth retrying. A throttled request was not accepted, so is always
+// retried. After a network error or a 5xx status the message may have been
+// accepted, and sending it again could deliver it twice, so it is only
+// retried if msg has an IdempotencyKey.
+func requestRetryable(msg *SMSMessage, err error) bool {
+	if errors.Is(err, ErrThrottled) {
+		return true
+	}
+	if msg.IdempotencyKey == "" {
+		return false
+	}
+	var serverErr *serverError
+	var urlErr *url.Error
+	return errors.As(err, &serverErr) || errors.As(err, &urlErr)
+}
+
+// retryError describes the failure of a message Nexmo accepted, for the
+// Backoff strategy.
+func retryError(resp *MessageResponse) error {
+	for _, report := range resp.Messages {
+		if report.Status == ResponseThrottled {
+			return ErrThrottled
+		}
+		if report.Status != ResponseSuccess {
+			return fmt.Errorf("Message failed: %s (%s)", report.Status, report.ErrorText)
+		}
+	}
+	return errors.New("Message failed")
+}
@@ -0,0 +1,185 @@
+package nexmo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var retryableTests = []struct {
+	statuses []ResponseCode
+	want     bool
+}{
+	{[]ResponseCode{ResponseThrottled}, true},
+	{[]ResponseCode{ResponseInternalError, ResponseThrottled}, true},
+	{[]ResponseCode{ResponseSuccess, ResponseThrottled}, false},
+	{[]ResponseCode{ResponseNumberBarred}, false},
+	{nil, false},
+}
+
+func TestRetryable(t *testing.T) {
+	for _, test := range retryableTests {
+		resp := &MessageResponse{}
+		for _, status := range test.statuses {
+			resp.Messages = append(resp.Messages, MessageReport{Status: status})
+		}
+		if got := Retryable(resp, nil); got != test.want {
+			t.Errorf("Retryable(%v) = %v, want %v", test.statuses, got, test.want)
+		}
+	}
+	if Retryable(nil, ErrThrottled) {
+		t.Error("Retryable should leave request errors to Client.Backoff")
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	var calls int
+	throttleFirst := true
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 && throttleFirst {
+			w.Write([]byte(`{"message-count": "1", "messages": [{"status": "1", "error-text": "Throughput Rate Exceeded"}]}`))
+			return
+		}
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "6", "error-text": "Unroutable message"}]}`))
+	}))
+	msg := func() *SMSMessage {
+		return &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	}
+
+	// Without a Backoff nothing is retried.
+	resp, err := nexmo.SMS.Send(msg())
+	if err != nil || calls != 1 || resp.Messages[0].Status != ResponseThrottled {
+		t.Errorf("Send without Backoff = %+v, %v after %d calls", resp, err, calls)
+	}
+
+	calls = 0
+	nexmo.Backoff = ConstantBackoff{Delay: time.Second, MaxAttempts: 5}
+	start := clk.Now()
+	resp, err = nexmo.SMS.Send(msg())
+	if err != nil || calls != 2 || resp.Messages[0].Status != ResponseInvalidMessage {
+		t.Errorf("Send with Backoff = %+v, %v after %d calls, want the second response", resp, err, calls)
+	}
+	if waited := clk.Now().Sub(start); waited != time.Second {
+		t.Errorf("Waited %v before retrying, want 1s", waited)
+	}
+
+	calls, throttleFirst = 0, false
+	nexmo.RetryPredicate = func(resp *MessageResponse, err error) bool {
+		return resp != nil && resp.Messages[0].ErrorText == "Unroutable message"
+	}
+	resp, err = nexmo.SMS.Send(msg())
+	if err != nil || calls != 5 {
+		t.Errorf("Send with RetryPredicate = %+v, %v after %d calls, want 5 calls", resp, err, calls)
+	}
+}
+
+func TestSendRetriesFailedRequests(t *testing.T) {
+	var calls int
+	status := http.StatusBadGateway
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	nexmo.Backoff = ConstantBackoff{Delay: time.Second, MaxAttempts: 3}
+	msg := func(key string) *SMSMessage {
+		return &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", IdempotencyKey: key}
+	}
+
+	// The message may have been accepted, so a 5xx is not retried without
+	// an IdempotencyKey.
+	if _, err := nexmo.SMS.Send(msg("")); err == nil || calls != 1 {
+		t.Errorf("Send after a 502 = %v after %d calls, want an error after 1", err, calls)
+	}
+
+	// With a key, the request and message retries share one limit rather
+	// than compounding.
+	calls = 0
+	nexmo.RetryPredicate = func(*MessageResponse, error) bool { return true }
+	if _, err := nexmo.SMS.Send(msg("order-1")); err == nil || calls != 3 {
+		t.Errorf("Send with an IdempotencyKey after 502s = %v after %d calls, want an error after 3", err, calls)
+	}
+
+	// A throttled request was not accepted, so is always retried.
+	calls, status = 0, http.StatusTooManyRequests
+	nexmo.RetryPredicate = nil
+	if _, err := nexmo.SMS.Send(msg("")); !errors.Is(err, ErrThrottled) || calls != 3 {
+		t.Errorf("Send after 429s = %v after %d calls, want ErrThrottled after 3", err, calls)
+	}
+}
+
+func TestSendCorrelationID(t *testing.T) {
+	var calls int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Write([]byte(`{"message-count": "1", "messages": [{"status": "1", "message-id": "0A00000001"}]}`))
+			return
+		}
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000002"}]}`))
+	}))
+	nexmo.Backoff = ConstantBackoff{Delay: time.Second, MaxAttempts: 5}
+
+	var seen []string
+	nexmo.RetryPredicate = func(resp *MessageResponse, err error) bool {
+		seen = append(seen, resp.CorrelationID)
+		return Retryable(resp, err)
+	}
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	resp, err := nexmo.SMS.Send(msg)
+	if err != nil || calls != 2 {
+		t.Fatalf("Send = %+v, %v after %d calls, want a retried success", resp, err, calls)
+	}
+	if len(resp.CorrelationID) != 36 || len(seen) != 2 || seen[0] != resp.CorrelationID || seen[1] != resp.CorrelationID {
+		t.Errorf("Attempts had correlation IDs %v, want %q for both", seen, resp.CorrelationID)
+	}
+
+	next, err := nexmo.SMS.Send(msg)
+	if err != nil || next.CorrelationID == resp.CorrelationID {
+		t.Errorf("Second Send had correlation ID %q, want a new one", next.CorrelationID)
+	}
+}
+
+func TestSendOptions(t *testing.T) {
+	var calls int
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "1", "error-text": "Throughput Rate Exceeded"}]}`))
+	}))
+	nexmo.Backoff = ConstantBackoff{Delay: time.Second, MaxAttempts: 5}
+	msg := func() *SMSMessage {
+		return &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	}
+
+	ctx := context.Background()
+	if _, err := nexmo.SMS.SendContextWithOptions(ctx, msg(), SendOptions{Backoff: NoRetry}); err != nil || calls != 1 {
+		t.Errorf("Send with NoRetry = %v after %d calls, want 1 call", err, calls)
+	}
+
+	calls = 0
+	perCall := SendOptions{Backoff: ConstantBackoff{Delay: time.Second, MaxAttempts: 2}}
+	if _, err := nexmo.SMS.SendContextWithOptions(ctx, msg(), perCall); err != nil || calls != 2 {
+		t.Errorf("Send with a per-call Backoff = %v after %d calls, want 2 calls", err, calls)
+	}
+
+	calls = 0
+	if _, err := nexmo.SMS.Send(msg()); err != nil || calls != 5 {
+		t.Errorf("Send with the Client's Backoff = %v after %d calls, want 5 calls", err, calls)
+	}
+
+	calls = 0
+	nexmo.Backoff = nil
+	nexmo.SMS.RateLimit = 0.001
+	if _, err := nexmo.SMS.Send(msg()); err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	start := clk.Now()
+	if _, err := nexmo.SMS.SendContextWithOptions(ctx, msg(), SendOptions{BypassRateLimit: true}); err != nil || calls != 2 {
+		t.Errorf("Send bypassing the rate limit = %v after %d calls", err, calls)
+	}
+	if waited := clk.Now().Sub(start); waited != 0 {
+		t.Errorf("Send bypassing the rate limit waited %v", waited)
+	}
+}
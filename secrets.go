@@ -0,0 +1,100 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// DefaultSecretMaxAge is how long an API secret is used for before rotation
+// when Account.SecretMaxAge is not set.
+const DefaultSecretMaxAge = 90 * 24 * time.Hour
+
+// Secret is one of the account's API secrets. Nexmo only returns its ID and
+// when it was created, never the secret itself.
+type Secret struct {
+	ID        string    `json:"id"`
+	CreatedAt NexmoTime `json:"created_at"`
+}
+
+// ExpiresAt returns when the secret is due for rotation under maxAge.
+// Secrets don't expire at Nexmo, so this is a policy of the caller's.
+func (s Secret) ExpiresAt(maxAge time.Duration) time.Time {
+	return s.CreatedAt.Add(maxAge)
+}
+
+/*
+	GET https://api.nexmo.com/accounts/{api_key}/secrets
+*/
+
+// ListSecrets returns the API secrets of the account, at most two.
+func (nexmo *Account) ListSecrets() (secrets []Secret, err error) {
+	var response struct {
+		Embedded struct {
+			Secrets []Secret `json:"secrets"`
+		} `json:"_embedded"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	}
+
+	r, _ := http.NewRequest("GET", nexmo.client.apiURL+"/accounts/"+nexmo.client.apiKey+"/secrets", nil)
+	r.SetBasicAuth(nexmo.client.apiKey, nexmo.client.apiSecret)
+
+	defer nexmo.client.wrapError(r, &err)
+	resp, err := nexmo.client.do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if response.Title == "" {
+			return nil, fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("Listing secrets failed: %s %s", response.Title, response.Detail)
+	}
+	for i := range response.Embedded.Secrets {
+		secret := &response.Embedded.Secrets[i]
+		secret.CreatedAt = nexmo.client.localTime(secret.CreatedAt)
+	}
+	return response.Embedded.Secrets, nil
+}
+
+// SecretsExpiringSoon returns the secrets due for rotation within the given
+// duration, oldest first, including any already overdue. Nexmo doesn't
+// expire secrets, so a secret is due SecretMaxAge after it was created.
+func (nexmo *Account) SecretsExpiringSoon(within time.Duration) ([]Secret, error) {
+	if within < 0 {
+		return nil, errors.New("Invalid duration specified")
+	}
+	maxAge := nexmo.SecretMaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultSecretMaxAge
+	}
+
+	secrets, err := nexmo.ListSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := nexmo.client.clock.Now().Add(within)
+	var expiring []Secret
+	for _, secret := range secrets {
+		if secret.ExpiresAt(maxAge).Before(deadline) {
+			expiring = append(expiring, secret)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].CreatedAt.Before(expiring[j].CreatedAt.Time)
+	})
+	return expiring, nil
+}
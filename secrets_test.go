@@ -0,0 +1,48 @@
+package nexmo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSecretsExpiringSoon(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); r.URL.Path != "/accounts/"+API_KEY+"/secrets" ||
+			user != API_KEY || pass != API_SECRET {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"title": "Invalid credentials", "detail": "The credentials supplied are invalid"}`))
+			return
+		}
+		// The fake clock starts at 2020-01-01.
+		w.Write([]byte(`{"_embedded": {"secrets": [
+			{"id": "ad6dc56f-07b5-46e1-a527-85530e625800", "created_at": "2019-12-20T00:00:00Z"},
+			{"id": "bd6dc56f-07b5-46e1-a527-85530e625801", "created_at": "2019-09-01T00:00:00Z"}
+		]}}`))
+	}))
+
+	secrets, err := nexmo.Account.ListSecrets()
+	if err != nil || len(secrets) != 2 {
+		t.Fatalf("ListSecrets = %+v, %v, want 2 secrets", secrets, err)
+	}
+
+	// The older secret is 122 days old, past the default 90 day maximum.
+	expiring, err := nexmo.Account.SecretsExpiringSoon(7 * 24 * time.Hour)
+	if err != nil {
+		t.Fatal("SecretsExpiringSoon failed with error:", err)
+	}
+	if len(expiring) != 1 || expiring[0].ID != "bd6dc56f-07b5-46e1-a527-85530e625801" {
+		t.Errorf("Expiring secrets = %+v, want only the older one", expiring)
+	}
+
+	nexmo.Account.SecretMaxAge = 30 * 24 * time.Hour
+	expiring, err = nexmo.Account.SecretsExpiringSoon(30 * 24 * time.Hour)
+	if err != nil || len(expiring) != 2 || !expiring[0].CreatedAt.Before(expiring[1].CreatedAt.Time) {
+		t.Errorf("Expiring secrets with a 30 day maximum = %+v, %v, want both, oldest first", expiring, err)
+	}
+
+	nexmo.apiKey = "wrong"
+	if _, err := nexmo.Account.ListSecrets(); err == nil {
+		t.Error("Expected an error for invalid credentials")
+	}
+}
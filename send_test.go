@@ -0,0 +1,260 @@
+package nexmo
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var sendValidationTests = []struct {
+	msg     SMSMessage
+	wantErr bool
+}{
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}, false},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Text: "Hello"}, false},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Text}, true},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Binary, UDH: []byte{0x00}, Body: make([]byte, 139)}, false},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Binary, UDH: []byte{0x00}, Body: make([]byte, 140)}, true},
+	{SMSMessage{From: "gonexmo", To: "447700900000"}, true},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Unicode}, true},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: VCard, VCard: "BEGIN:VCARD"}, false},
+	{SMSMessage{To: "447700900000", Type: Text, Text: "Hello"}, true},
+	{SMSMessage{From: "gonexmo", Type: Text, Text: "Hello"}, true},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", NetworkCode: "23410"}, false},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", NetworkCode: "310260"}, false},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", NetworkCode: "O2-UK"}, true},
+	{SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", NetworkCode: "2341"}, true},
+}
+
+func TestSendValidation(t *testing.T) {
+	var sent int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001"}]}`))
+	}))
+
+	for _, test := range sendValidationTests {
+		msg := test.msg
+		sent = 0
+		_, err := nexmo.SMS.Send(&msg)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Send(%+v) error = %v, want error: %v", test.msg, err, test.wantErr)
+		}
+		if test.wantErr && sent != 0 {
+			t.Errorf("Send(%+v) reached Nexmo despite failing validation", test.msg)
+		}
+	}
+}
+
+func TestSendTestMode(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Test mode sent a request to Nexmo")
+	}))
+	nexmo.SMS.TestMode = true
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text,
+		Text: strings.Repeat("a", 200), ClientReference: "ref"}
+	resp, err := nexmo.SMS.Send(msg)
+	if err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	if resp.MessageCount != 2 || len(resp.Messages) != 2 {
+		t.Fatalf("Got %d reports, want one for each of the 2 segments", len(resp.Messages))
+	}
+	if err := resp.Validate(); err != nil {
+		t.Error("Test mode response is invalid:", err)
+	}
+	for _, report := range resp.Messages {
+		if report.Status != ResponseSuccess || report.MessageID == "" ||
+			report.To != msg.To || report.ClientReference != "ref" || report.MessagePrice != "0.00000000" {
+			t.Errorf("Unexpected test mode report: %+v", report)
+		}
+	}
+
+	if _, err := nexmo.SMS.Send(&SMSMessage{From: "gonexmo", To: "447700900000", Type: Text}); err == nil {
+		t.Error("Test mode should still validate messages")
+	}
+}
+
+func TestSendXML(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sms/xml" || r.Header.Get("Accept") != "application/xml" {
+			t.Errorf("Got %s with Accept %q, want /sms/xml and application/xml",
+				r.URL.Path, r.Header.Get("Accept"))
+		}
+		w.Write([]byte(`<?xml version='1.0' encoding='UTF-8' ?>
+<mt-submission-response>
+  <messages count='1'>
+    <message>
+      <to>447700900000</to>
+      <messageId>0A0000000123ABCD1</messageId>
+      <status>0</status>
+      <clientRef>ref</clientRef>
+      <remainingBalance>3.14159265</remainingBalance>
+      <messagePrice>0.03330000</messagePrice>
+      <network>12345</network>
+    </message>
+  </messages>
+</mt-submission-response>`))
+	}))
+	nexmo.XML = true
+
+	resp, err := nexmo.SMS.Send(&SMSMessage{From: "gonexmo", To: "447700900000", Type: Text,
+		Text: "Hello", ClientReference: "ref"})
+	if err != nil {
+		t.Fatal("Send failed with error:", err)
+	}
+	want := MessageReport{Status: ResponseSuccess, MessageID: "0A0000000123ABCD1", To: "447700900000",
+		ClientReference: "ref", RemainingBalance: "3.14159265", MessagePrice: "0.03330000", Network: "12345"}
+	if resp.MessageCount != 1 || len(resp.Messages) != 1 || resp.Messages[0] != want {
+		t.Errorf("Got %+v, want one report %+v", resp, want)
+	}
+}
+
+func TestSendWarnings(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message-count": "2", "messages": [{"status": "0", "message-id": "0A00000001", "route": "a"}], "region": "eu"}`))
+	}))
+	var seen []Warning
+	nexmo.OnWarning = func(w Warning) { seen = append(seen, w) }
+
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	resp, err := nexmo.SMS.Send(msg)
+	if err != nil {
+		t.Fatal("Send with warnings failed with error:", err)
+	}
+	want := []Warning{
+		{WarningMessageCountMismatch, "message-count 2 does not match 1 message reports"},
+		{WarningUnknownField, "unknown response field region"},
+		{WarningUnknownField, "unknown response field messages[0].route"},
+	}
+	if !reflect.DeepEqual(resp.Warnings, want) {
+		t.Errorf("Warnings = %v, want %v", resp.Warnings, want)
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("OnWarning got %v, want %v", seen, want)
+	}
+}
+
+// TestSendConcurrent hammers one SMS service from many goroutines with every
+// stateful feature enabled. Run it with -race.
+func TestSendConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var keyed int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if strings.HasPrefix(r.PostForm.Get("text"), "Hello") {
+			mu.Lock()
+			keyed++
+			mu.Unlock()
+		}
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "0", "message-id": "0A00000001", "message-price": "0.01", "remaining-balance": "10.0"}]}`))
+	}))
+	sms := nexmo.SMS
+	sms.RateLimit = 1000
+	sms.AdaptiveRateLimit = true
+	sms.CountryRateLimits = map[string]float64{"GB": 500}
+	sms.CollectStats = true
+	sms.Transliterate = true
+	sms.AutoUnicode = true
+	sms.SenderConfig = map[string]string{"GB": "gonexmo", "US": "14155550100"}
+	sms.BatchConcurrency = 4
+	nexmo.OnRequest = func(RequestEvent) {}
+	nexmo.OnWarning = func(Warning) {}
+
+	const goroutines, sends = 16, 20
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < sends; i++ {
+				to := "447700900000"
+				if i%2 == 1 {
+					to = "14155550100"
+				}
+				msg := &SMSMessage{To: to, Type: Text, Text: "Hello “world”", IdempotencyKey: fmt.Sprintf("key-%d", i%5)}
+				resp, err := sms.Send(msg)
+				if err != nil {
+					t.Errorf("Send failed with error: %v", err)
+					return
+				}
+				newSendResult(to, resp, nil)
+				sms.Stats()
+			}
+			sms.SendBatch([]*SMSMessage{
+				{To: "447700900001", Type: Text, Text: "Batch"},
+				{To: "447700900002", Type: Text, Text: "Batch"},
+			})
+		}(g)
+	}
+	wg.Wait()
+
+	if keyed != 5 {
+		t.Errorf("Sent %d messages with 5 idempotency keys, want 5", keyed)
+	}
+	if stats := sms.Stats(); stats.Messages != 5+goroutines*2 {
+		t.Errorf("Counted %d messages, want %d", stats.Messages, 5+goroutines*2)
+	}
+}
+
+func TestToValuesAccountRef(t *testing.T) {
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+	if vals := msg.ToValues(); vals["account-ref"] != nil {
+		t.Errorf("account-ref = %q, want it not sent", vals["account-ref"])
+	}
+
+	msg.AccountRef = "customer-1234"
+	if got := msg.ToValues().Get("account-ref"); got != "customer-1234" {
+		t.Errorf("account-ref = %q, want customer-1234", got)
+	}
+}
+
+func TestMetadataNotSent(t *testing.T) {
+	msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello",
+		Metadata: map[string]string{"campaign": "spring"}}
+	for name, values := range msg.ToValues() {
+		if strings.Contains(name, "campaign") || strings.Contains(strings.Join(values, ","), "spring") {
+			t.Errorf("Metadata sent in the form as %s=%v", name, values)
+		}
+	}
+
+	b, err := msg.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON failed with error:", err)
+	}
+	if strings.Contains(string(b), "campaign") || strings.Contains(string(b), "spring") {
+		t.Errorf("Metadata sent in the JSON: %s", b)
+	}
+}
+
+func TestSetTTL(t *testing.T) {
+	for _, d := range []time.Duration{0, MinTTL - time.Millisecond, MaxTTL + time.Millisecond} {
+		msg := &SMSMessage{TTL: 1234}
+		if err := msg.SetTTL(d); err == nil {
+			t.Errorf("SetTTL(%v) succeeded, want an error outside %v to %v", d, MinTTL, MaxTTL)
+		}
+		if msg.TTL != 1234 {
+			t.Errorf("SetTTL(%v) failed but changed TTL to %d", d, msg.TTL)
+		}
+	}
+
+	for d, want := range map[time.Duration]string{
+		MinTTL:           "20000",
+		90 * time.Minute: "5400000",
+		MaxTTL:           "604800000",
+	} {
+		msg := &SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello"}
+		if err := msg.SetTTL(d); err != nil {
+			t.Errorf("SetTTL(%v) failed with error: %v", d, err)
+			continue
+		}
+		if got := msg.ToValues().Get("ttl"); got != want {
+			t.Errorf("SetTTL(%v) sent ttl=%q, want %q", d, got, want)
+		}
+	}
+}
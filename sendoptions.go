@@ -0,0 +1,47 @@
+package nexmo
+
+import "time"
+
+// NoRetry is a BackoffStrategy that never retries, for turning off the
+// Client's Backoff for a single send with SendOptions.
+var NoRetry BackoffStrategy = ConstantBackoff{}
+
+// SendOptions override the Client and SMS defaults for a single send with
+// SendContextWithOptions. Zero fields leave the defaults in place.
+type SendOptions struct {
+	// Backoff replaces Client.Backoff, both for retrying the request and
+	// for retrying the message. Use NoRetry to not retry at all.
+	Backoff BackoffStrategy
+
+	// RetryPredicate replaces Client.RetryPredicate.
+	RetryPredicate func(resp *MessageResponse, err error) bool
+
+	// Timeout bounds the whole send, including waiting in the rate limit
+	// queue and any retries.
+	Timeout time.Duration
+
+	// BypassRateLimit sends the message straight away instead of through
+	// the SMS.RateLimit queue, e.g. for an urgent OTP behind a marketing
+	// campaign. It still waits for SMS.CountryRateLimits, which carriers
+	// enforce, and counts towards Nexmo's own limits.
+	BypassRateLimit bool
+}
+
+// backoff returns the Backoff for a send made with opts.
+func (c *Client) backoff(opts SendOptions) BackoffStrategy {
+	if b := opts.Backoff; b != nil {
+		return b
+	}
+	return c.Backoff
+}
+
+// retryPredicate returns the RetryPredicate for a send made with opts.
+func (c *Client) retryPredicate(opts SendOptions) func(*MessageResponse, error) bool {
+	if p := opts.RetryPredicate; p != nil {
+		return p
+	}
+	if c.RetryPredicate != nil {
+		return c.RetryPredicate
+	}
+	return Retryable
+}
@@ -53,7 +53,7 @@ type RecvdMessage struct {
 	ID string
 
 	// Time when Nexmo started to push the message to you.
-	Timestamp time.Time
+	Timestamp NexmoTime
 
 	// Parameters for conactenated messages:
 	Concatenated bool // Set to true if a MO concatenated message is detected.
@@ -92,15 +92,22 @@ type DeliveryReceipt struct {
 	Status          string    `json:"status"`
 	ErrorCode       string    `json:"err-code"`
 	Price           string    `json:"price"`
-	SCTS            time.Time `json:"scts"`
-	Timestamp       time.Time `json:"message-timestamp"`
+	SCTS            NexmoTime `json:"scts"`
+	Timestamp       NexmoTime `json:"message-timestamp"`
 	ClientReference string    `json:"client-ref"`
 }
 
 // NewDeliveryHandler creates a new http.HandlerFunc that can be used to listen
 // for deivery receipts from the Nexmo server. Any receipts received will be
-// decoded nad passed to the out chan.
+// decoded nad passed to the out chan. Times are read as UTC; use
+// SMS.NewDeliveryHandler to read them in Client.Location.
 func NewDeliveryHandler(out chan *DeliveryReceipt, verifyIPs bool) http.HandlerFunc {
+	return newDeliveryHandler(func(m *DeliveryReceipt) { out <- m }, verifyIPs, time.UTC)
+}
+
+// newDeliveryHandler creates a delivery receipt handler that passes each
+// decoded receipt to deliver, reading times without a zone in loc.
+func newDeliveryHandler(deliver func(*DeliveryReceipt), verifyIPs bool, loc *time.Location) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if verifyIPs {
 			// Check if the request came from Nexmo
@@ -138,8 +145,8 @@ func NewDeliveryHandler(out chan *DeliveryReceipt, verifyIPs bool) http.HandlerF
 			return
 		}
 
-		// Convert the timestamp to a time.Time.
-		timestamp, err := time.Parse("0601021504", t)
+		// Convert the timestamp to a NexmoTime.
+		timestamp, err := ParseNexmoTimeInLocation(t, loc)
 		if err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
@@ -153,8 +160,8 @@ func NewDeliveryHandler(out chan *DeliveryReceipt, verifyIPs bool) http.HandlerF
 			return
 		}
 
-		// Convert the timestamp to a time.Time.
-		timestamp, err = time.Parse("2006-01-02 15:04:05", t)
+		// Convert the timestamp to a NexmoTime.
+		timestamp, err = ParseNexmoTimeInLocation(t, loc)
 		if err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
@@ -162,16 +169,29 @@ func NewDeliveryHandler(out chan *DeliveryReceipt, verifyIPs bool) http.HandlerF
 
 		m.Timestamp = timestamp
 
-		// Pass it out on the chan
-		out <- m
+		// Pass it out
+		deliver(m)
 	}
 
 }
 
 // NewMessageHandler creates a new http.HandlerFunc that can be used to listen
 // for new messages from the Nexmo server. Any new messages received will be
-// decoded and passed to the out chan.
+// decoded and passed to the out chan. Timestamps are read as UTC; use
+// SMS.NewMessageHandler to read them in Client.Location.
 func NewMessageHandler(out chan *RecvdMessage, verifyIPs bool) http.HandlerFunc {
+	return newMessageHandler(out, verifyIPs, time.UTC)
+}
+
+// NewMessageHandler is like the package level NewMessageHandler, but reads
+// timestamps in Client.Location.
+func (c *SMS) NewMessageHandler(out chan *RecvdMessage, verifyIPs bool) http.HandlerFunc {
+	return newMessageHandler(out, verifyIPs, c.client.location())
+}
+
+// newMessageHandler creates an inbound message handler that passes each
+// decoded message to out, reading timestamps without a zone in loc.
+func newMessageHandler(out chan *RecvdMessage, verifyIPs bool, loc *time.Location) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if verifyIPs {
 			// Check if the request came from Nexmo
@@ -246,8 +266,8 @@ func NewMessageHandler(out chan *RecvdMessage, verifyIPs bool) http.HandlerFunc
 			return
 		}
 
-		// Convert the timestamp to a time.Time.
-		timestamp, err := time.Parse("2006-01-02 15:04:05", t)
+		// Convert the timestamp to a NexmoTime.
+		timestamp, err := ParseNexmoTimeInLocation(t, loc)
 		if err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
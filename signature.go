@@ -0,0 +1,80 @@
+package nexmo
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SignatureMethod is the algorithm used to sign requests, configured in the
+// Nexmo dashboard alongside the signature secret.
+type SignatureMethod int
+
+// Signature methods.
+const (
+	MD5Hash SignatureMethod = iota
+	MD5HMAC
+	SHA1HMAC
+	SHA256HMAC
+	SHA512HMAC
+)
+
+// ComputeSignature computes the signature of params with the signature secret
+// using method, as Nexmo does for signed requests and webhooks.
+//
+// The signed string is built by sorting the parameters by name, leaving out
+// "sig", and appending "&name=value" for each, with any "&" or "=" in the
+// value replaced by "_". With MD5Hash the secret is appended and the MD5 of
+// the result is returned as lower case hex. The HMAC methods use the secret as
+// the key and return upper case hex.
+func ComputeSignature(params url.Values, secret string, method SignatureMethod) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != "sig" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	valueReplacer := strings.NewReplacer("&", "_", "=", "_")
+	var signed strings.Builder
+	for _, name := range names {
+		signed.WriteString("&" + name + "=" + valueReplacer.Replace(params.Get(name)))
+	}
+
+	var newHash func() hash.Hash
+	switch method {
+	case MD5Hash:
+		sum := md5.Sum([]byte(signed.String() + secret))
+		return hex.EncodeToString(sum[:])
+	case MD5HMAC:
+		newHash = md5.New
+	case SHA1HMAC:
+		newHash = sha1.New
+	case SHA256HMAC:
+		newHash = sha256.New
+	case SHA512HMAC:
+		newHash = sha512.New
+	default:
+		return ""
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(signed.String()))
+	return strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// VerifySignature reports whether the "sig" parameter of params is a valid
+// signature of the other parameters.
+func VerifySignature(params url.Values, secret string, method SignatureMethod) bool {
+	sig := strings.ToLower(params.Get("sig"))
+	expected := strings.ToLower(ComputeSignature(params, secret, method))
+	return sig != "" && subtle.ConstantTimeCompare([]byte(sig), []byte(expected)) == 1
+}
@@ -0,0 +1,48 @@
+package nexmo
+
+import (
+	"net/url"
+	"testing"
+)
+
+var signatureParams = url.Values{
+	"to":        {"447700900000"},
+	"text":      {"a=b&c"},
+	"timestamp": {"1461605396"},
+}
+
+func TestComputeSignature(t *testing.T) {
+	// The signed string is "&text=a_b_c&timestamp=1461605396&to=447700900000".
+	tests := []struct {
+		method SignatureMethod
+		want   string
+	}{
+		{MD5Hash, "5f21bd8006c3f7543071a2fbfef0d65f"},
+		{SHA256HMAC, "8B6E822AF6FD999E99A6F6BCF8F191CFC6CED99D93A69E888825E9D63BF1092A"},
+	}
+	for _, test := range tests {
+		got := ComputeSignature(signatureParams, "secret", test.method)
+		if got != test.want {
+			t.Errorf("ComputeSignature(%d) = %s, want %s", test.method, got, test.want)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	params := url.Values{}
+	for name, values := range signatureParams {
+		params[name] = values
+	}
+	params.Set("sig", ComputeSignature(params, "secret", SHA1HMAC))
+
+	if !VerifySignature(params, "secret", SHA1HMAC) {
+		t.Error("Valid signature was rejected")
+	}
+	if VerifySignature(params, "other", SHA1HMAC) {
+		t.Error("Signature with the wrong secret was accepted")
+	}
+	params.Set("to", "447700900001")
+	if VerifySignature(params, "secret", SHA1HMAC) {
+		t.Error("Signature of modified params was accepted")
+	}
+}
@@ -1,19 +1,147 @@
 package nexmo
 
 import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"io/ioutil"
-	"log"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // SMS represents the SMS API functions for sending text messages.
+//
+// An SMS service is safe for concurrent use by multiple goroutines once its
+// fields are set. A message must not be sent by more than one at once, as
+// Send fills in some of its fields.
 type SMS struct {
 	client *Client
+
+	// BlockedNetworkTypes lists the Number Insight network types (e.g.
+	// NetworkVirtual) that SendWithCarrierCheck refuses to send to. Block
+	// NetworkLandlinePremium and NetworkShortCode to guard against toll
+	// fraud, where OTPs are requested for numbers that earn the fraudster a
+	// share of the charge. Send itself only applies NetworkShortCode, which
+	// needs no lookup.
+	BlockedNetworkTypes []string
+
+	// RateLimit, if greater than zero, is the maximum number of messages
+	// sent per second. Sends over the limit are queued and drained by a
+	// background worker; Send blocks until its message has been sent.
+	RateLimit float64
+
+	// CountryRateLimits maps ISO country codes (e.g. "IN") to the maximum
+	// number of messages per second sent to that country, detected from the
+	// number sent to, on top of RateLimit. Carriers filter traffic that
+	// arrives faster than they accept, and their limits differ by country.
+	CountryRateLimits map[string]float64
+
+	// AdaptiveRateLimit makes the rate limiter slow down when Nexmo
+	// throttles sends, halving the rate each time, and speed back up to
+	// RateLimit gradually as sends succeed. Use it when the account's limit
+	// isn't known, setting RateLimit to the most it could be.
+	AdaptiveRateLimit bool
+
+	// QueueSize is the number of sends that may wait for the rate limiter
+	// before QueueOverflow applies.
+	QueueSize int
+
+	// QueueOverflow decides what Send does when the queue is full.
+	QueueOverflow OverflowPolicy
+
+	// Transliterate replaces characters outside the GSM-7 alphabet in text
+	// messages with GSM-7 lookalikes before sending, so a message with e.g.
+	// curly quotes isn't sent as more expensive UCS-2. The changes are
+	// reported in MessageResponse.Substitutions.
+	Transliterate bool
+
+	// TransliterationTable overrides DefaultTransliterations.
+	TransliterationTable map[rune]string
+
+	// RejectUnsupportedCharacters makes Send fail with an
+	// *UnsupportedCharactersError when a text or Unicode message has
+	// characters Nexmo would strip, rather than send it without them.
+	RejectUnsupportedCharacters bool
+
+	// TestMode makes Send validate messages as usual but not send them,
+	// returning a successful response with a made up message ID and a
+	// price of zero for each segment instead. Nexmo's SMS API has no test
+	// or sandbox mode of its own, so this keeps CI and staging from
+	// spending credit.
+	TestMode bool
+
+	// AutoUnicode sends a text message as Unicode when, after any
+	// transliteration, its text can't be encoded in GSM-7. The encoding
+	// used is reported in MessageResponse.UsedEncoding.
+	AutoUnicode bool
+
+	// MaxSegments, if greater than zero, is the most segments a message may
+	// be split into. Send refuses longer messages.
+	MaxSegments int
+
+	// StrictNumbers makes Send check that To is in international format.
+	// A number that looks national, such as "07911 123456", is converted
+	// from DefaultCountry, or fails with ErrLooksNational if DefaultCountry
+	// is empty or the number is the wrong length for it.
+	StrictNumbers  bool
+	DefaultCountry string
+
+	// SenderConfig maps ISO country codes (e.g. "GB") to the From used for
+	// messages to that country when a message's From is empty.
+	SenderConfig map[string]string
+
+	// IdempotencyStore remembers responses to messages sent with an
+	// IdempotencyKey. Defaults to an in-memory store keeping them for 24
+	// hours; nil disables the check.
+	IdempotencyStore IdempotencyStore
+
+	// BatchConcurrency is the number of messages the batch senders send at
+	// once. Defaults to 1.
+	BatchConcurrency int
+
+	// MinBalance, if greater than zero, makes the batch senders check the
+	// account balance before sending anything, and fail every message with
+	// ErrInsufficientBalance if it is below MinBalance, so a campaign isn't
+	// cut short by running out of credit. Send never checks it, to save the
+	// extra request.
+	MinBalance float64
+
+	// SkipDuplicateRecipients makes the batch senders send only the first
+	// of several messages to the same number, compared in international
+	// format, and fail the rest with ErrDuplicateRecipient, so a list with
+	// repeated numbers isn't charged twice.
+	SkipDuplicateRecipients bool
+
+	// CollectStats keeps running totals of the encoding, segments and cost
+	// of the messages sent, returned by Stats.
+	CollectStats bool
+	stats        sendStats
+
+	queueOnce    sync.Once
+	queue        chan *queuedSend
+	queueMutex   sync.RWMutex
+	queueClosed  bool
+	queueDone    chan struct{}
+	inFlight     chan struct{}
+	pending      pendingSends
+	flushing     int32
+	flushNow     chan struct{}
+	rateMutex    sync.Mutex
+	adaptiveRate float64
+	countryNext  map[string]time.Time
+
+	idempotencyLocks keyLocks
+
+	receipts receiptRegistry
 }
 
 // SMS message types.
@@ -26,6 +154,13 @@ const (
 	VCard   = "vcard"
 )
 
+// MessageClass is the class of an SMS message. The zero value leaves the
+// class unset, so the handset decides what to do with the message.
+//
+// Flash used to be the zero value, and the class was never sent. Flash is now
+// 1, and a set Class is sent as message-class 0 (Flash) to 3 (Forward), so
+// code that set Class, e.g. to Standard, now changes what the handset does
+// with the message.
 type MessageClass int
 
 // SMS message classes.
@@ -33,7 +168,7 @@ const (
 	// This type of SMS message is displayed on the mobile screen without being
 	// saved in the message store or on the SIM card; unless explicitly saved
 	// by the mobile user.
-	Flash MessageClass = iota
+	Flash MessageClass = iota + 1
 
 	// This message is to be stored in the device memory or the SIM card
 	// (depending on memory availability).
@@ -62,15 +197,35 @@ var messageClassMap = map[MessageClass]string{
 func (m MessageClass) String() string {
 	return messageClassMap[m]
 }
+
+// value returns the message-class parameter Nexmo expects for m, from 0 for
+// Flash to 3 for Forward.
+func (m MessageClass) value() int {
+	return int(m) - 1
+}
+
+// MarshalJSON encodes m as the message-class value Nexmo expects.
+func (m MessageClass) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.value())
+}
 func (m *SMSMessage) MarshalJSON() ([]byte, error) {
+	msg := *m
+	if msg.RequestStatusReport {
+		msg.StatusReportRequired = 1
+	}
+	// Body and UDH are sent as hex, not base64 like other []byte.
 	return json.Marshal(struct {
 		ApiKey    string `json:"api_key"`
 		ApiSecret string `json:"api_secret"`
+		Body      string `json:"body,omitempty"`
+		UDH       string `json:"udh,omitempty"`
 		SMSMessage
 	}{
 		ApiKey:     m.apiKey,
 		ApiSecret:  m.apiSecret,
-		SMSMessage: *m,
+		Body:       hex.EncodeToString(m.Body),
+		UDH:        hex.EncodeToString(m.UDH),
+		SMSMessage: msg,
 	})
 }
 
@@ -78,25 +233,63 @@ func (m *SMSMessage) MarshalJSON() ([]byte, error) {
 type SMSMessage struct {
 	apiKey               string
 	apiSecret            string
+	correlationID        string
 	From                 string       `json:"from"`
 	To                   string       `json:"to"`
 	Type                 string       `json:"type"`
 	Text                 string       `json:"text,omitempty"`              // Optional.
-	StatusReportRequired int          `json:"status-report-req,omitempty"` // Optional.
+	RequestStatusReport  bool         `json:"-"`                           // Optional. Request a delivery receipt.
+	StatusReportRequired int          `json:"status-report-req,omitempty"` // Deprecated: use RequestStatusReport.
 	ClientReference      string       `json:"client-ref,omitempty"`        // Optional.
+	AccountRef           string       `json:"account-ref,omitempty"`       // Optional.
 	NetworkCode          string       `json:"network-code,omitempty"`      // Optional.
 	VCard                string       `json:"vcard,omitempty"`             // Optional.
 	VCal                 string       `json:"vcal,omitempty"`              // Optional.
-	TTL                  int          `json:"ttl,omitempty"`               // Optional.
+	TTL                  int          `json:"ttl,omitempty"`               // Optional, in milliseconds. See SetTTL.
 	Class                MessageClass `json:"message-class,omitempty"`     // Optional.
-	Body                 []byte       `json:"body,omitempty"`              // Required for Binary message.
-	UDH                  []byte       `json:"udh,omitempty"`               // Required for Binary message.
+	Body                 []byte       `json:"body,omitempty"`              // Required for Binary message. Raw bytes, sent hex encoded.
+	UDH                  []byte       `json:"udh,omitempty"`               // Required for Binary message. Raw bytes, sent hex encoded.
+	ProtocolID           int          `json:"protocol-id,omitempty"`       // Optional. TP-PID for Binary and WAP Push messages.
 
 	// The following is only for type=wappush
 
 	Title    string `json:"title,omitempty"`    // Title shown to recipient
 	URL      string `json:"url,omitempty"`      // WAP Push URL
 	Validity int    `json:"validity,omitempty"` // Duration WAP Push is available in milliseconds
+
+	// Language is not sent to Nexmo. If set to one of the Language
+	// constants, SegmentCount counts text that doesn't fit the default GSM-7
+	// alphabet with that language's national shift tables, e.g. to estimate
+	// the cost on a route known to apply them. Nexmo has no parameter for the tables, so it doesn't
+	// change how the message is sent: AutoUnicode still sends text outside
+	// the default GSM-7 alphabet as Unicode.
+	Language string `json:"-"`
+
+	// IdempotencyKey is not sent to Nexmo. If set, a later Send of a message
+	// with the same key returns the first response instead of sending again.
+	// See SMS.IdempotencyStore.
+	IdempotencyKey string `json:"-"`
+
+	// Metadata is not sent to Nexmo. It is passed to Client.OnRequest and
+	// Client.OnResponse, e.g. to label metrics with an internal user or
+	// campaign ID.
+	Metadata map[string]string `json:"-"`
+}
+
+// Bounds on the time-to-live Nexmo accepts for a message.
+const (
+	MinTTL = 20 * time.Second
+	MaxTTL = 7 * 24 * time.Hour
+)
+
+// SetTTL sets how long Nexmo will attempt delivery of the message. It is
+// preferred over setting TTL directly, since TTL is in milliseconds.
+func (msg *SMSMessage) SetTTL(d time.Duration) error {
+	if d < MinTTL || d > MaxTTL {
+		return fmt.Errorf("TTL must be between %v and %v", MinTTL, MaxTTL)
+	}
+	msg.TTL = int(d / time.Millisecond)
+	return nil
 }
 
 func (msg *SMSMessage) ToValues() url.Values {
@@ -107,12 +300,17 @@ func (msg *SMSMessage) ToValues() url.Values {
 	if msg.Text != "" {
 		vals.Add("text", msg.Text)
 	}
-	if msg.StatusReportRequired != 0 {
+	if msg.RequestStatusReport {
+		vals.Add("status-report-req", "1")
+	} else if msg.StatusReportRequired != 0 {
 		vals.Add("status-report-req", strconv.Itoa(msg.StatusReportRequired))
 	}
 	if msg.ClientReference != "" {
 		vals.Add("client-ref", msg.ClientReference)
 	}
+	if msg.AccountRef != "" {
+		vals.Add("account-ref", msg.AccountRef)
+	}
 	if msg.NetworkCode != "" {
 		vals.Add("network-code", msg.NetworkCode)
 	}
@@ -125,12 +323,26 @@ func (msg *SMSMessage) ToValues() url.Values {
 	if msg.TTL != 0 {
 		vals.Add("ttl", strconv.Itoa(msg.TTL))
 	}
-	// TODO support message-class
+	if msg.Class != 0 {
+		vals.Add("message-class", strconv.Itoa(msg.Class.value()))
+	}
 	if len(msg.Body) > 0 {
-		vals.Add("body", string(msg.Body))
+		vals.Add("body", hex.EncodeToString(msg.Body))
 	}
 	if len(msg.UDH) > 0 {
-		vals.Add("udh", string(msg.UDH))
+		vals.Add("udh", hex.EncodeToString(msg.UDH))
+	}
+	if msg.ProtocolID != 0 {
+		vals.Add("protocol-id", strconv.Itoa(msg.ProtocolID))
+	}
+	if msg.Title != "" {
+		vals.Add("title", msg.Title)
+	}
+	if msg.URL != "" {
+		vals.Add("url", msg.URL)
+	}
+	if msg.Validity != 0 {
+		vals.Add("validity", strconv.Itoa(msg.Validity))
 	}
 	return vals
 }
@@ -141,6 +353,41 @@ func (c ResponseCode) String() string {
 	return responseCodeMap[c]
 }
 
+// MarshalJSON encodes c as a string, as Nexmo does.
+func (c ResponseCode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.Itoa(int(c)))
+}
+
+// UnmarshalJSON decodes c from a string, as Nexmo normally sends it, or from
+// a number.
+func (c *ResponseCode) UnmarshalJSON(data []byte) error {
+	var n flexInt
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*c = ResponseCode(n)
+	return nil
+}
+
+// flexInt is an integer that may be encoded as a JSON string or number.
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	var i int
+	if err := json.Unmarshal(data, &i); err != nil {
+		return fmt.Errorf("Invalid integer %s", data)
+	}
+	*n = flexInt(i)
+	return nil
+}
+
 const (
 	ResponseSuccess ResponseCode = iota
 	ResponseThrottled
@@ -185,14 +432,14 @@ var responseCodeMap = map[ResponseCode]string{
 
 // MessageReport is the "status report" for a single SMS sent via the Nexmo API
 type MessageReport struct {
-	Status           ResponseCode `json:"status,string"`
-	MessageID        string       `json:"message-id"`
-	To               string       `json:"to"`
-	ClientReference  string       `json:"client-ref"`
-	RemainingBalance string       `json:"remaining-balance"`
-	MessagePrice     string       `json:"message-price"`
-	Network          string       `json:"network"`
-	ErrorText        string       `json:"error-text"`
+	Status           ResponseCode `json:"status" xml:"status"`
+	MessageID        string       `json:"message-id" xml:"messageId"`
+	To               string       `json:"to" xml:"to"`
+	ClientReference  string       `json:"client-ref" xml:"clientRef"`
+	RemainingBalance string       `json:"remaining-balance" xml:"remainingBalance"`
+	MessagePrice     string       `json:"message-price" xml:"messagePrice"`
+	Network          string       `json:"network" xml:"network"`
+	ErrorText        string       `json:"error-text" xml:"errorText"`
 }
 
 // MessageResponse contains the response from Nexmo's API after we attempt to
@@ -201,10 +448,154 @@ type MessageReport struct {
 type MessageResponse struct {
 	MessageCount int             `json:"message-count,string"`
 	Messages     []MessageReport `json:"messages"`
+
+	// Substitutions lists the characters replaced in the message text when
+	// SMS.Transliterate is set.
+	Substitutions []Substitution `json:"-" xml:"-"`
+
+	// UsedEncoding is EncodingGSM7 or EncodingUCS2 for text and Unicode
+	// messages, as detected by the package from the text sent. It is empty
+	// for other message types.
+	UsedEncoding string `json:"-" xml:"-"`
+
+	// CorrelationID is a random UUID generated by the package for each call
+	// to Send, and the same for every attempt made by retries, unlike the
+	// message IDs Nexmo assigns to each attempt. It tags the verbose log
+	// lines of the send.
+	CorrelationID string `json:"-" xml:"-"`
+
+	// Warnings lists soft issues found in the response, such as a
+	// message-count that doesn't match the reports or unknown fields. They
+	// never fail the send; see also Client.OnWarning.
+	Warnings []Warning `json:"-" xml:"-"`
+
+	// Currency is the account currency that MessagePrice and
+	// RemainingBalance are in, such as "EUR", which Nexmo doesn't state in
+	// the response. It is only set once the Client has cached it; call
+	// Account.Currency or Client.RefreshReferenceData first.
+	Currency string `json:"-" xml:"-"`
+}
+
+// UnmarshalJSON decodes the response, accepting message-count as either a
+// string or a number.
+func (r *MessageResponse) UnmarshalJSON(data []byte) error {
+	type plain MessageResponse
+	aux := struct {
+		*plain
+		MessageCount flexInt `json:"message-count"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.MessageCount = int(aux.MessageCount)
+	return nil
+}
+
+// UnmarshalXML decodes the mt-submission-response returned by Nexmo's XML
+// interface, used when Client.XML is set.
+func (r *MessageResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var aux struct {
+		Messages struct {
+			Count   int             `xml:"count,attr"`
+			Reports []MessageReport `xml:"message"`
+		} `xml:"messages"`
+	}
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	r.MessageCount = aux.Messages.Count
+	r.Messages = aux.Messages.Reports
+	return nil
+}
+
+// RemainingBalance returns the lowest account balance reported by any part of
+// the message, i.e. the balance after the last part was charged, and false
+// if no part reported one. It is the balance at the time of sending, not a
+// live query: use Account.GetBalance for that.
+func (r *MessageResponse) RemainingBalance() (float64, bool) {
+	balance, ok := 0.0, false
+	for _, report := range r.Messages {
+		value, err := strconv.ParseFloat(report.RemainingBalance, 64)
+		if err != nil {
+			continue
+		}
+		if !ok || value < balance {
+			balance, ok = value, true
+		}
+	}
+	return balance, ok
+}
+
+// Cost returns the total price of all parts of the message in Currency.
+func (r *MessageResponse) Cost() Money {
+	cost := Money{Currency: r.Currency}
+	for _, report := range r.Messages {
+		if price, err := strconv.ParseFloat(report.MessagePrice, 64); err == nil {
+			cost.Amount += price
+		}
+	}
+	return cost
+}
+
+// Balance returns RemainingBalance in Currency.
+func (r *MessageResponse) Balance() (Money, bool) {
+	balance, ok := r.RemainingBalance()
+	return Money{balance, r.Currency}, ok
+}
+
+// Validate checks that the response is internally consistent: MessageCount
+// must match the number of reports, and no message ID may appear twice.
+func (r *MessageResponse) Validate() error {
+	if r.MessageCount != len(r.Messages) {
+		return fmt.Errorf("Message count %d does not match %d message reports",
+			r.MessageCount, len(r.Messages))
+	}
+
+	seen := make(map[string]bool, len(r.Messages))
+	for _, report := range r.Messages {
+		if report.MessageID == "" {
+			continue
+		}
+		if seen[report.MessageID] {
+			return fmt.Errorf("Duplicate message ID %s in response", report.MessageID)
+		}
+		seen[report.MessageID] = true
+	}
+	return nil
 }
 
 // Send the message using the specified SMS client.
 func (c *SMS) Send(msg *SMSMessage) (*MessageResponse, error) {
+	return c.SendContext(context.Background(), msg)
+}
+
+// SendContext is like Send, but the request is made with ctx, which can
+// cancel it or carry a logger set with WithLogger.
+func (c *SMS) SendContext(ctx context.Context, msg *SMSMessage) (*MessageResponse, error) {
+	return c.SendContextWithOptions(ctx, msg, SendOptions{})
+}
+
+// SendContextWithOptions is like SendContext, but opts override the Client
+// and SMS defaults for this message.
+func (c *SMS) SendContextWithOptions(ctx context.Context, msg *SMSMessage, opts SendOptions) (*MessageResponse, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	if c.StrictNumbers {
+		to, err := strictMSISDN(msg.To, c.DefaultCountry)
+		if err != nil {
+			return nil, err
+		}
+		msg.To = to
+	}
+
+	if len(msg.From) <= 0 && c.SenderConfig != nil {
+		msg.From = c.SenderConfig[countryForMSISDN(msg.To)]
+	}
+
 	if len(msg.From) <= 0 {
 		return nil, errors.New("Invalid From field specified")
 	}
@@ -217,10 +608,23 @@ func (c *SMS) Send(msg *SMSMessage) (*MessageResponse, error) {
 		return nil, errors.New("Client reference too long")
 	}
 
-	var messageResponse *MessageResponse
+	if isShortCode(msg.To) {
+		if err := c.checkNetworkType(NetworkShortCode, msg.To); err != nil {
+			return nil, err
+		}
+	}
+
+	if msg.NetworkCode != "" && !isNetworkCode(msg.NetworkCode) {
+		return nil, errors.New("Invalid NetworkCode field specified")
+	}
 
 	switch msg.Type {
-	case Text:
+	case "", Text:
+		// An empty Type is sent as text by Nexmo.
+		if len(msg.Text) <= 0 {
+			return nil, errors.New("Invalid message text")
+		}
+	case VCal, VCard:
 	case Unicode:
 		if len(msg.Text) <= 0 {
 			return nil, errors.New("Invalid message text")
@@ -229,57 +633,252 @@ func (c *SMS) Send(msg *SMSMessage) (*MessageResponse, error) {
 		if len(msg.UDH) == 0 || len(msg.Body) == 0 {
 			return nil, errors.New("Invalid binary message")
 		}
+		if length := len(msg.UDH) + len(msg.Body); length > MaxBinaryLength {
+			return nil, fmt.Errorf("Binary message is %d bytes, more than the maximum of %d; split it with SplitBinary",
+				length, MaxBinaryLength)
+		}
 
 	case WAPPush:
 		if len(msg.URL) == 0 || len(msg.Title) == 0 {
 			return nil, errors.New("Invalid WAP Push parameters")
 		}
+	default:
+		return nil, fmt.Errorf("Invalid message type %q", msg.Type)
+	}
+
+	if msg.Language != "" && nationalAlphabets[msg.Language] == nil {
+		return nil, fmt.Errorf("Unsupported national language %q", msg.Language)
+	}
+
+	var substitutions []Substitution
+	if c.Transliterate && (msg.Type == "" || msg.Type == Text) {
+		table := c.TransliterationTable
+		if table == nil {
+			table = DefaultTransliterations
+		}
+		msg.Text, substitutions = transliterate(msg.Text, table)
+	}
+
+	if c.RejectUnsupportedCharacters && (msg.Type == "" || msg.Type == Text || msg.Type == Unicode) {
+		if unsupported := UnsupportedCharacters(msg.Text); len(unsupported) > 0 {
+			return nil, &UnsupportedCharactersError{unsupported}
+		}
+	}
+
+	if c.AutoUnicode && (msg.Type == "" || msg.Type == Text) && msg.usesUCS2() {
+		msg.Type = Unicode
+	}
+	usedEncoding := msg.usedEncoding()
+
+	if c.MaxSegments > 0 {
+		if segments := msg.SegmentCount(); segments > c.MaxSegments {
+			return nil, fmt.Errorf("Message is %d segments, more than the maximum of %d",
+				segments, c.MaxSegments)
+		}
 	}
 	if !c.client.useOauth {
 		msg.apiKey = c.client.apiKey
 		msg.apiSecret = c.client.apiSecret
 	}
 
-	client := &http.Client{}
+	msg.correlationID = newCorrelationID()
 
+	if msg.IdempotencyKey != "" && c.IdempotencyStore != nil {
+		defer c.idempotencyLocks.lock(msg.IdempotencyKey)()
+		if messageResponse, ok := c.IdempotencyStore.Get(msg.IdempotencyKey); ok {
+			return messageResponse, nil
+		}
+	}
+
+	var messageResponse *MessageResponse
+	var err error
+	switch {
+	case c.TestMode:
+		c.client.logf(ctx, "[%s] Test mode, not sending message to %s", msg.correlationID, msg.To)
+		messageResponse = testResponse(msg)
+		messageResponse.CorrelationID = msg.correlationID
+	default:
+		if err = c.waitForCountry(ctx, msg.To); err != nil {
+			break
+		}
+		if c.RateLimit > 0 && !opts.BypassRateLimit {
+			messageResponse, err = c.enqueue(ctx, msg, opts)
+		} else {
+			messageResponse, err = c.send(ctx, msg, opts)
+		}
+	}
+
+	if messageResponse != nil {
+		messageResponse.Substitutions = substitutions
+		messageResponse.UsedEncoding = usedEncoding
+		messageResponse.Currency = c.client.cachedCurrency()
+	}
+	if err == nil {
+		c.recordStats(msg, messageResponse)
+	}
+
+	if err == nil && msg.IdempotencyKey != "" && c.IdempotencyStore != nil {
+		c.IdempotencyStore.Put(msg.IdempotencyKey, messageResponse)
+	}
+	return messageResponse, err
+}
+
+// testResponse returns the response TestMode gives for msg.
+func testResponse(msg *SMSMessage) *MessageResponse {
+	segments := msg.SegmentCount()
+	response := &MessageResponse{MessageCount: segments}
+	for i := 0; i < segments; i++ {
+		response.Messages = append(response.Messages, MessageReport{
+			Status:          ResponseSuccess,
+			MessageID:       fmt.Sprintf("TEST%012X", rand.Int63n(1<<48)),
+			To:              msg.To,
+			ClientReference: msg.ClientReference,
+			MessagePrice:    "0.00000000",
+			Network:         msg.NetworkCode,
+		})
+	}
+	return response
+}
+
+// sendOnce performs the HTTP request for a message that has already been
+// validated by Send. It doesn't retry it; send does.
+func (c *SMS) sendOnce(ctx context.Context, msg *SMSMessage) (messageResponse *MessageResponse, err error) {
 	var r *http.Request
 
 	messageValues := msg.ToValues()
 	messageValues.Add("api_key", msg.apiKey)
 	messageValues.Add("api_secret", msg.apiSecret)
 	encodedForm := messageValues.Encode()
-	if c.client.VerboseLogging {
-		log.Println("NEXMO: Sending encoded form:", encodedForm)
+	c.client.logf(ctx, "[%s] Sending encoded form: %s", msg.correlationID, redactParams(messageValues).Encode())
+	format := "json"
+	if c.client.XML {
+		format = "xml"
 	}
-	r, _ = http.NewRequest("POST", apiRoot+"/sms/json", strings.NewReader(encodedForm))
+	r, _ = http.NewRequest("POST", c.client.restURL+"/sms/"+format, strings.NewReader(encodedForm))
+	r = r.WithContext(withMetadata(withCorrelationID(ctx, msg.correlationID), msg.Metadata))
 
-	r.Header.Add("Accept", "application/json")
+	r.Header.Add("Accept", "application/"+format)
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	if c.client.VerboseLogging {
-		log.Printf("NEXMO: Sending request: %+v\n", r)
-	}
+	c.client.logf(ctx, "Sending request: %+v", r)
 
-	resp, err := client.Do(r)
+	defer c.client.wrapError(r, &err)
+	resp, err := c.client.doBackoff(r, nil)
 
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if c.client.VerboseLogging {
-		log.Println("NEXMO: Response status code:", resp.StatusCode)
+	c.client.logf(ctx, "Response status code: %d", resp.StatusCode)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, ErrThrottled
+	}
+	if resp.StatusCode >= 500 {
+		return nil, &serverError{resp.StatusCode}
 	}
 
 	body, _ := ioutil.ReadAll(resp.Body)
 
-	if c.client.VerboseLogging {
-		log.Println("NEXMO: Response:", string(body))
-	}
+	c.client.logf(ctx, "Response: %s", body)
 
-	err = json.Unmarshal(body, &messageResponse)
+	if c.client.XML {
+		err = xml.Unmarshal(body, &messageResponse)
+	} else {
+		err = json.Unmarshal(body, &messageResponse)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if messageResponse == nil {
+		return nil, errors.New("Empty response from Nexmo")
+	}
+	messageResponse.CorrelationID = msg.correlationID
+	messageResponse.Warnings = responseWarnings(body, messageResponse, !c.client.XML)
+	c.client.warn(ctx, messageResponse.Warnings)
 	return messageResponse, nil
 }
+
+// newCorrelationID returns a random version 4 UUID.
+func newCorrelationID() string {
+	var b [16]byte
+	crand.Read(b[:])
+	b[6] = b[6]&0x0F | 0x40
+	b[8] = b[8]&0x3F | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// isNetworkCode reports whether s is a valid MCCMNC: a three digit mobile
+// country code followed by a two or three digit mobile network code.
+func isNetworkCode(s string) bool {
+	if len(s) != 5 && len(s) != 6 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveNetworkCode sets the message's NetworkCode to the network its
+// destination currently belongs to, looked up with Number Insight Standard,
+// so Nexmo routes it to the right network even if the number was ported.
+func (c *SMS) ResolveNetworkCode(msg *SMSMessage) error {
+	if len(msg.To) <= 0 {
+		return errors.New("Invalid To field specified")
+	}
+
+	networkCode, err := c.client.Insight.NetworkCode(msg.To)
+	if err != nil {
+		return err
+	}
+	msg.NetworkCode = networkCode
+	return nil
+}
+
+// ErrBlockedNetworkType is returned when sending to a number whose network
+// type is listed in SMS.BlockedNetworkTypes.
+var ErrBlockedNetworkType = errors.New("Network type is blocked")
+
+// checkNetworkType returns an error wrapping ErrBlockedNetworkType if
+// networkType is listed in BlockedNetworkTypes.
+func (c *SMS) checkNetworkType(networkType, to string) error {
+	for _, blocked := range c.BlockedNetworkTypes {
+		if networkType == blocked {
+			return fmt.Errorf("Refusing to send to %s number %s: %w", networkType, to, ErrBlockedNetworkType)
+		}
+	}
+	return nil
+}
+
+// SendWithCarrierCheck looks up the destination with Number Insight Standard
+// before sending. The message is refused if the destination's network type is
+// listed in BlockedNetworkTypes, otherwise its NetworkCode is set to the
+// resolved carrier so Nexmo can route it directly.
+func (c *SMS) SendWithCarrierCheck(msg *SMSMessage) (*MessageResponse, error) {
+	if len(msg.To) <= 0 {
+		return nil, errors.New("Invalid To field specified")
+	}
+
+	if isShortCode(msg.To) {
+		// Number Insight can't look up short codes; Send checks them.
+		return c.Send(msg)
+	}
+
+	insight, err := c.client.Insight.Standard(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkNetworkType(insight.CurrentCarrier.NetworkType, msg.To); err != nil {
+		return nil, err
+	}
+
+	if insight.CurrentCarrier.NetworkCode != "" {
+		msg.NetworkCode = insight.CurrentCarrier.NetworkCode
+	}
+	return c.Send(msg)
+}
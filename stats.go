@@ -0,0 +1,115 @@
+package nexmo
+
+import (
+	"strconv"
+	"sync"
+)
+
+// SendStats are running totals of the messages sent by an SMS service with
+// CollectStats set, for seeing where SMS spend goes.
+type SendStats struct {
+	// Messages is the number of messages Nexmo accepted, split into
+	// GSM7Messages, UCS2Messages and OtherMessages by the encoding used.
+	Messages      int
+	GSM7Messages  int
+	UCS2Messages  int
+	OtherMessages int
+
+	// Segments is the number of segments of all the messages, as billed.
+	Segments int
+
+	// ActualCost is the total price Nexmo charged.
+	ActualCost float64
+
+	// EstimatedCost is the total price the cached pricing predicted, for the
+	// EstimatedMessages messages to countries whose pricing was cached when
+	// they were sent. Pricing is never fetched to estimate a message, so
+	// call Client.RefreshReferenceData first for full coverage.
+	EstimatedCost     float64
+	EstimatedMessages int
+}
+
+// AverageSegments returns the mean number of segments per message.
+func (s SendStats) AverageSegments() float64 {
+	if s.Messages == 0 {
+		return 0
+	}
+	return float64(s.Segments) / float64(s.Messages)
+}
+
+type sendStats struct {
+	mu    sync.Mutex
+	stats SendStats
+}
+
+// Stats returns a snapshot of the totals collected since the last
+// ResetStats, or since CollectStats was set.
+func (c *SMS) Stats() SendStats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	return c.stats.stats
+}
+
+// ResetStats sets the collected totals back to zero.
+func (c *SMS) ResetStats() {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.stats = SendStats{}
+}
+
+// recordStats adds a message Nexmo accepted to the totals.
+func (c *SMS) recordStats(msg *SMSMessage, resp *MessageResponse) {
+	if !c.CollectStats {
+		return
+	}
+
+	var actual float64
+	for _, report := range resp.Messages {
+		if report.Status != ResponseSuccess {
+			return
+		}
+		if price, err := strconv.ParseFloat(report.MessagePrice, 64); err == nil {
+			actual += price
+		}
+	}
+	estimate, estimated := c.client.estimateCost(msg.To, msg.NetworkCode, len(resp.Messages))
+
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	s := &c.stats.stats
+	s.Messages++
+	switch resp.UsedEncoding {
+	case EncodingGSM7:
+		s.GSM7Messages++
+	case EncodingUCS2:
+		s.UCS2Messages++
+	default:
+		s.OtherMessages++
+	}
+	s.Segments += len(resp.Messages)
+	s.ActualCost += actual
+	if estimated {
+		s.EstimatedCost += estimate
+		s.EstimatedMessages++
+	}
+}
+
+// estimateCost returns the price of sending segments to the number to from
+// the cached pricing, using the price of networkCode if it has one, and
+// false if the pricing of the destination isn't cached.
+func (c *Client) estimateCost(to, networkCode string, segments int) (float64, bool) {
+	c.refData.mu.RLock()
+	entry, ok := c.refData.pricing[countryForMSISDN(to)]
+	c.refData.mu.RUnlock()
+	if !ok || c.clock.Now().Sub(entry.fetchedAt) >= c.referenceDataTTL() {
+		return 0, false
+	}
+
+	price := entry.pricing.DefaultPrice
+	for _, network := range entry.pricing.Networks {
+		if networkCode != "" && network.NetworkCode == networkCode {
+			price = network.Price
+		}
+	}
+	return price * float64(segments), true
+}
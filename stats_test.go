@@ -0,0 +1,63 @@
+package nexmo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendStats(t *testing.T) {
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/account/get-pricing/") {
+			w.Write([]byte(`{"countryCode": "GB", "currency": "EUR", "defaultPrice": "0.04000000",
+				"networks": [{"networkCode": "23410", "price": "0.03000000", "currency": "EUR"}]}`))
+			return
+		}
+		r.ParseForm()
+		switch {
+		case r.PostForm.Get("to") == "447700900002":
+			w.Write([]byte(`{"message-count": "1", "messages": [{"status": "7", "error-text": "Number barred"}]}`))
+		case r.PostForm.Get("type") == Unicode:
+			w.Write([]byte(`{"message-count": "2", "messages": [
+				{"status": "0", "message-id": "0A00000002", "message-price": "0.04000000"},
+				{"status": "0", "message-id": "0A00000003", "message-price": "0.04000000"}]}`))
+		default:
+			w.Write([]byte(`{"message-count": "1", "messages": [
+				{"status": "0", "message-id": "0A00000001", "message-price": "0.03000000"}]}`))
+		}
+	}))
+	nexmo.SMS.CollectStats = true
+	nexmo.SMS.AutoUnicode = true
+
+	send := func(msg *SMSMessage) {
+		if _, err := nexmo.SMS.Send(msg); err != nil {
+			t.Fatal("Send failed with error:", err)
+		}
+	}
+	send(&SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", NetworkCode: "23410"})
+	if _, err := nexmo.Account.GetPricing("GB"); err != nil {
+		t.Fatal("GetPricing failed with error:", err)
+	}
+	send(&SMSMessage{From: "gonexmo", To: "447700900000", Type: Text, Text: "Hello", NetworkCode: "23410"})
+	send(&SMSMessage{From: "gonexmo", To: "447700900001", Type: Text, Text: strings.Repeat("П", 100)})
+	send(&SMSMessage{From: "gonexmo", To: "447700900002", Type: Text, Text: "Hello"})
+
+	stats := nexmo.SMS.Stats()
+	if stats.Messages != 3 || stats.GSM7Messages != 2 || stats.UCS2Messages != 1 || stats.Segments != 4 {
+		t.Errorf("Unexpected counts %+v", stats)
+	}
+	if avg := stats.AverageSegments(); avg < 1.333 || avg > 1.334 {
+		t.Errorf("AverageSegments() = %v, want 4/3", avg)
+	}
+	if stats.ActualCost < 0.13999 || stats.ActualCost > 0.14001 {
+		t.Errorf("ActualCost = %v, want 0.14", stats.ActualCost)
+	}
+	if stats.EstimatedMessages != 2 || stats.EstimatedCost < 0.10999 || stats.EstimatedCost > 0.11001 {
+		t.Errorf("Estimated %d messages at %v, want 2 at 0.11", stats.EstimatedMessages, stats.EstimatedCost)
+	}
+
+	nexmo.SMS.ResetStats()
+	if stats := nexmo.SMS.Stats(); stats != (SendStats{}) {
+		t.Errorf("Stats after ResetStats = %+v, want zero", stats)
+	}
+}
@@ -0,0 +1,59 @@
+package nexmo
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultTransliterations maps common characters outside the GSM-7 alphabet
+// to GSM-7 replacements, used when SMS.Transliterate is set.
+var DefaultTransliterations = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '`': "'", '´': "'",
+	'“': "\"", '”': "\"", '„': "\"", '‟': "\"",
+	'–': "-", '—': "-", '‐': "-", '−': "-", '•': "-",
+	'…': "...", ' ': " ", '\t': " ",
+	'á': "a", 'â': "a", 'ã': "a", 'ā': "a",
+	'Á': "A", 'À': "A", 'Â': "A", 'Ã': "A", 'Ā': "A",
+	'ç': "c", 'ć': "c", 'č': "c", 'Ć': "C", 'Č': "C",
+	'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e",
+	'È': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ę': "E",
+	'í': "i", 'î': "i", 'ï': "i", 'Í': "I", 'Ì': "I", 'Î': "I", 'Ï': "I",
+	'ó': "o", 'ô': "o", 'õ': "o", 'ō': "o", 'Ó': "O", 'Ò': "O", 'Ô': "O", 'Õ': "O", 'Ō': "O",
+	'ú': "u", 'û': "u", 'ū': "u", 'Ú': "U", 'Ù': "U", 'Û': "U", 'Ū': "U",
+	'ý': "y", 'ÿ': "y", 'Ý': "Y",
+	'ł': "l", 'Ł': "L", 'ś': "s", 'š': "s", 'Ś': "S", 'Š': "S",
+	'ź': "z", 'ż': "z", 'ž': "z", 'Ź': "Z", 'Ż': "Z", 'Ž': "Z",
+	'œ': "oe", 'Œ': "OE",
+}
+
+// Substitution records a character replaced by transliteration.
+type Substitution struct {
+	Original    rune
+	Replacement string
+	Count       int
+}
+
+// transliterate replaces the characters of s that are outside the GSM-7
+// alphabet and have an entry in table. It returns the new text and the
+// substitutions made, ordered by the original character.
+func transliterate(s string, table map[rune]string) (string, []Substitution) {
+	counts := make(map[rune]int)
+	var b strings.Builder
+	for _, r := range s {
+		if replacement, ok := table[r]; ok && !gsm7Basic[r] && !gsm7Extension[r] {
+			b.WriteString(replacement)
+			counts[r]++
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	substitutions := make([]Substitution, 0, len(counts))
+	for r, count := range counts {
+		substitutions = append(substitutions, Substitution{r, table[r], count})
+	}
+	sort.Slice(substitutions, func(i, j int) bool {
+		return substitutions[i].Original < substitutions[j].Original
+	})
+	return b.String(), substitutions
+}
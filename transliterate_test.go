@@ -0,0 +1,28 @@
+package nexmo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	got, substitutions := transliterate("“Café” – naïve…", DefaultTransliterations)
+	if want := "\"Café\" - naive..."; got != want {
+		t.Errorf("transliterate() = %q, want %q", got, want)
+	}
+
+	want := []Substitution{
+		{'ï', "i", 1},
+		{'–', "-", 1},
+		{'“', "\"", 1},
+		{'”', "\"", 1},
+		{'…', "...", 1},
+	}
+	if !reflect.DeepEqual(substitutions, want) {
+		t.Errorf("substitutions = %v, want %v", substitutions, want)
+	}
+
+	if _, ok := septetCount(got); !ok {
+		t.Error("Transliterated text is not GSM-7")
+	}
+}
@@ -30,7 +30,7 @@ type USSDMessage struct {
 }
 
 // Send the message using the specified USSD client.
-func (c *USSD) Send(msg *USSDMessage) (*MessageResponse, error) {
+func (c *USSD) Send(msg *USSDMessage) (messageResponse *MessageResponse, err error) {
 	if len(msg.From) <= 0 {
 		return nil, errors.New("Invalid From field specified")
 	}
@@ -43,8 +43,6 @@ func (c *USSD) Send(msg *USSDMessage) (*MessageResponse, error) {
 		return nil, errors.New("Client reference too long")
 	}
 
-	var messageResponse *MessageResponse
-
 	values := make(url.Values)
 
 	if len(msg.Text) <= 0 {
@@ -80,20 +78,18 @@ func (c *USSD) Send(msg *USSDMessage) (*MessageResponse, error) {
 	values.Set("to", msg.To)
 	values.Set("from", msg.From)
 
-	client := &http.Client{}
 	valuesReader := bytes.NewReader([]byte(values.Encode()))
 	var r *http.Request
-	r, _ = http.NewRequest("POST", apiRoot+endpoint, valuesReader)
+	r, _ = http.NewRequest("POST", c.client.restURL+endpoint, valuesReader)
 
-	r.Header.Add("Accept", "application/json")
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(r)
-	defer resp.Body.Close()
-
+	defer c.client.wrapError(r, &err)
+	resp, err := c.client.do(r)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	body, _ := ioutil.ReadAll(resp.Body)
 
@@ -0,0 +1,96 @@
+package nexmo
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// VCardBuilder builds the contact card of a VCard message, for
+// SMSMessage.VCard. It produces vCard 2.1, which handsets read most widely.
+type VCardBuilder struct {
+	GivenName  string
+	FamilyName string
+	Tel        string // Optional, in international format, e.g. "+447700900000".
+	Email      string // Optional.
+	Org        string // Optional.
+}
+
+// Build returns the vCard text. A name is required, as is a phone number or
+// email address, since a card without either is of no use to the recipient.
+func (b VCardBuilder) Build() (string, error) {
+	if b.GivenName == "" && b.FamilyName == "" {
+		return "", errors.New("vCard needs a given or family name")
+	}
+	if b.Tel == "" && b.Email == "" {
+		return "", errors.New("vCard needs a phone number or email address")
+	}
+	for _, value := range []string{b.GivenName, b.FamilyName, b.Tel, b.Email, b.Org} {
+		if strings.ContainsAny(value, "\r\n") {
+			return "", errors.New("vCard fields can not contain line breaks")
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCARD\r\nVERSION:2.1\r\n")
+	sb.WriteString("N:" + escapeVValue(b.FamilyName) + ";" + escapeVValue(b.GivenName) + "\r\n")
+	sb.WriteString("FN:" + escapeVValue(strings.TrimSpace(b.GivenName+" "+b.FamilyName)) + "\r\n")
+	if b.Org != "" {
+		sb.WriteString("ORG:" + escapeVValue(b.Org) + "\r\n")
+	}
+	if b.Tel != "" {
+		sb.WriteString("TEL;CELL:" + b.Tel + "\r\n")
+	}
+	if b.Email != "" {
+		sb.WriteString("EMAIL;INTERNET:" + b.Email + "\r\n")
+	}
+	sb.WriteString("END:VCARD\r\n")
+	return sb.String(), nil
+}
+
+// VCalBuilder builds the event of a VCal message, for SMSMessage.VCal. It
+// produces vCalendar 1.0, which handsets read most widely.
+type VCalBuilder struct {
+	Summary  string
+	Start    time.Time
+	End      time.Time // Optional. Must not be before Start.
+	Location string    // Optional.
+}
+
+// vCalTimeFormat is the vCalendar date and time format, in UTC.
+const vCalTimeFormat = "20060102T150405Z"
+
+// Build returns the vCalendar text. A summary and start time are required.
+func (b VCalBuilder) Build() (string, error) {
+	if b.Summary == "" {
+		return "", errors.New("vCal needs a summary")
+	}
+	if b.Start.IsZero() {
+		return "", errors.New("vCal needs a start time")
+	}
+	if !b.End.IsZero() && b.End.Before(b.Start) {
+		return "", errors.New("vCal end time is before its start time")
+	}
+	if strings.ContainsAny(b.Summary+b.Location, "\r\n") {
+		return "", errors.New("vCal fields can not contain line breaks")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCALENDAR\r\nVERSION:1.0\r\nBEGIN:VEVENT\r\n")
+	sb.WriteString("SUMMARY:" + escapeVValue(b.Summary) + "\r\n")
+	sb.WriteString("DTSTART:" + b.Start.UTC().Format(vCalTimeFormat) + "\r\n")
+	if !b.End.IsZero() {
+		sb.WriteString("DTEND:" + b.End.UTC().Format(vCalTimeFormat) + "\r\n")
+	}
+	if b.Location != "" {
+		sb.WriteString("LOCATION:" + escapeVValue(b.Location) + "\r\n")
+	}
+	sb.WriteString("END:VEVENT\r\nEND:VCALENDAR\r\n")
+	return sb.String(), nil
+}
+
+// escapeVValue escapes the characters with a meaning in vCard and vCalendar
+// property values.
+func escapeVValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`).Replace(s)
+}
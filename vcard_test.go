@@ -0,0 +1,56 @@
+package nexmo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVCardBuilder(t *testing.T) {
+	card, err := VCardBuilder{GivenName: "Ada", FamilyName: "Lovelace", Tel: "+447700900000",
+		Email: "ada@example.com", Org: "Analytical; Engines"}.Build()
+	if err != nil {
+		t.Fatal("Build failed with error:", err)
+	}
+	want := "BEGIN:VCARD\r\nVERSION:2.1\r\nN:Lovelace;Ada\r\nFN:Ada Lovelace\r\n" +
+		"ORG:Analytical\\; Engines\r\nTEL;CELL:+447700900000\r\nEMAIL;INTERNET:ada@example.com\r\nEND:VCARD\r\n"
+	if card != want {
+		t.Errorf("Build() = %q, want %q", card, want)
+	}
+
+	invalid := []VCardBuilder{
+		{Tel: "+447700900000"},
+		{GivenName: "Ada"},
+		{GivenName: "Ada\r\nEND:VCARD", Tel: "+447700900000"},
+	}
+	for _, b := range invalid {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("Expected an error building %+v", b)
+		}
+	}
+}
+
+func TestVCalBuilder(t *testing.T) {
+	start := time.Date(2020, 1, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))
+	cal, err := VCalBuilder{Summary: "Dentist", Start: start, End: start.Add(30 * time.Minute),
+		Location: "12 High Street"}.Build()
+	if err != nil {
+		t.Fatal("Build failed with error:", err)
+	}
+	want := "BEGIN:VCALENDAR\r\nVERSION:1.0\r\nBEGIN:VEVENT\r\nSUMMARY:Dentist\r\n" +
+		"DTSTART:20200101T120000Z\r\nDTEND:20200101T123000Z\r\nLOCATION:12 High Street\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+	if cal != want {
+		t.Errorf("Build() = %q, want %q", cal, want)
+	}
+
+	invalid := []VCalBuilder{
+		{Start: start},
+		{Summary: "Dentist"},
+		{Summary: "Dentist", Start: start, End: start.Add(-time.Hour)},
+	}
+	for _, b := range invalid {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("Expected an error building %+v", b)
+		}
+	}
+}
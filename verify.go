@@ -0,0 +1,315 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Verify represents the Verify API functions for verifying a user's phone
+// number with a one-time code.
+type Verify struct {
+	client *Client
+}
+
+// VerifyRequest describes a new verification.
+type VerifyRequest struct {
+	Number        string
+	Brand         string // Included in the message sent to the user. At most MaxBrandLength characters.
+	SenderID      string // Optional. At most 11 letters and digits, or a number of up to 15 digits.
+	Country       string // Optional. Used if Number is in national format.
+	CodeLength    int    // Optional. 4 or 6 digits.
+	Lg            string // Optional. Language of the message, e.g. VerifyLangEnglishUS, in any case.
+	PINExpiry     int    // Optional. Seconds until the code expires.
+	NextEventWait int    // Optional. Seconds between delivery attempts.
+}
+
+// VerifyResponse is Nexmo's response to a verify request.
+type VerifyResponse struct {
+	RequestID string `json:"request_id"`
+	Status    int    `json:"status,string"`
+	ErrorText string `json:"error_text"`
+}
+
+// VerifyCheckResponse is Nexmo's response to a verify check.
+type VerifyCheckResponse struct {
+	RequestID string  `json:"request_id"`
+	EventID   string  `json:"event_id"`
+	Status    int     `json:"status,string"`
+	Price     float64 `json:"price,string"`
+	Currency  string  `json:"currency"`
+	ErrorText string  `json:"error_text"`
+}
+
+// Cost returns the price charged for the verification.
+func (r *VerifyCheckResponse) Cost() Money {
+	return Money{r.Price, r.Currency}
+}
+
+// MaxBrandLength is the most characters of VerifyRequest.Brand.
+const MaxBrandLength = 18
+
+// validateSenderID checks that id is an alphanumeric sender ID of at most 11
+// characters, or a numeric one of at most 15 digits, as the networks allow.
+func validateSenderID(id string) error {
+	const caveat = "; some countries, such as the US and Canada, don't allow alphanumeric sender IDs at all " +
+		"and replace them with a Nexmo number, and others need them registered first"
+
+	numeric := strings.Trim(id, "0123456789") == ""
+	switch {
+	case numeric && len(id) > 15:
+		return fmt.Errorf("Numeric sender ID %q is %d digits, more than the maximum of 15", id, len(id))
+	case numeric:
+		return nil
+	case len(id) > 11:
+		return fmt.Errorf("Alphanumeric sender ID %q is %d characters, more than the maximum of 11%s", id, len(id), caveat)
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == ' ') {
+			return fmt.Errorf("Sender ID %q has %q, but only letters, digits and spaces are allowed%s", id, r, caveat)
+		}
+	}
+	return nil
+}
+
+// Verify control commands.
+const (
+	VerifyCancel           = "cancel"
+	VerifyTriggerNextEvent = "trigger_next_event"
+)
+
+// VerifyControlResponse is Nexmo's response to a verify control command.
+type VerifyControlResponse struct {
+	Status    int    `json:"status,string"`
+	Command   string `json:"command"`
+	ErrorText string `json:"error_text"`
+}
+
+/*
+	POST https://api.nexmo.com/verify/json
+	POST https://api.nexmo.com/verify/check/json
+	POST https://api.nexmo.com/verify/control/json
+*/
+
+// Request starts a new verification, sending a code to req.Number.
+func (c *Verify) Request(req *VerifyRequest) (*VerifyResponse, error) {
+	if len(req.Number) <= 0 {
+		return nil, errors.New("Invalid number field specified")
+	}
+
+	if len(req.Brand) <= 0 {
+		return nil, errors.New("Invalid brand field specified")
+	}
+	if n := utf8.RuneCountInString(req.Brand); n > MaxBrandLength {
+		return nil, fmt.Errorf("Brand %q is %d characters, more than the maximum of %d", req.Brand, n, MaxBrandLength)
+	}
+
+	if req.SenderID != "" {
+		if err := validateSenderID(req.SenderID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify's languages are lower case, but locales are often written
+	// with an upper case region, e.g. "en-GB".
+	lg := strings.ToLower(req.Lg)
+	if lg != "" && !IsSupportedVerifyLang(lg) {
+		return nil, fmt.Errorf("Unsupported language %q, try %q", req.Lg, ClosestSupportedLang(req.Lg))
+	}
+
+	values := url.Values{}
+	values.Set("number", req.Number)
+	values.Set("brand", req.Brand)
+	if req.SenderID != "" {
+		values.Set("sender_id", req.SenderID)
+	}
+	if req.Country != "" {
+		values.Set("country", req.Country)
+	}
+	if req.CodeLength != 0 {
+		values.Set("code_length", strconv.Itoa(req.CodeLength))
+	}
+	if lg != "" {
+		values.Set("lg", lg)
+	}
+	if req.PINExpiry != 0 {
+		values.Set("pin_expiry", strconv.Itoa(req.PINExpiry))
+	}
+	if req.NextEventWait != 0 {
+		values.Set("next_event_wait", strconv.Itoa(req.NextEventWait))
+	}
+
+	var verifyResponse *VerifyResponse
+	err := c.post("/verify/json", values, &verifyResponse, func() error {
+		if verifyResponse.Status != 0 {
+			return verifyError(verifyResponse.Status, verifyResponse.ErrorText)
+		}
+		return nil
+	})
+	return verifyResponse, err
+}
+
+// Check checks the code the user entered for the verification requestID.
+func (c *Verify) Check(requestID, code string) (*VerifyCheckResponse, error) {
+	if len(requestID) <= 0 {
+		return nil, errors.New("Invalid request ID specified")
+	}
+
+	if len(code) <= 0 {
+		return nil, errors.New("Invalid code specified")
+	}
+
+	values := url.Values{}
+	values.Set("request_id", requestID)
+	values.Set("code", code)
+
+	var checkResponse *VerifyCheckResponse
+	err := c.post("/verify/check/json", values, &checkResponse, func() error {
+		if checkResponse.Status != 0 {
+			return verifyError(checkResponse.Status, checkResponse.ErrorText)
+		}
+		return nil
+	})
+	return checkResponse, err
+}
+
+// Control sends a command, VerifyCancel or VerifyTriggerNextEvent, for the
+// verification requestID.
+func (c *Verify) Control(requestID, command string) (*VerifyControlResponse, error) {
+	if len(requestID) <= 0 {
+		return nil, errors.New("Invalid request ID specified")
+	}
+
+	if command != VerifyCancel && command != VerifyTriggerNextEvent {
+		return nil, errors.New("Invalid command specified")
+	}
+
+	values := url.Values{}
+	values.Set("request_id", requestID)
+	values.Set("cmd", command)
+
+	var controlResponse *VerifyControlResponse
+	err := c.post("/verify/control/json", values, &controlResponse, func() error {
+		if controlResponse.Status == verifyStatusWrongState && command == VerifyCancel {
+			err := ErrVerifyCancelTooLate
+			if strings.Contains(controlResponse.ErrorText, "30 seconds") {
+				err = ErrVerifyCancelTooEarly
+			}
+			return fmt.Errorf("%w: %s", err, controlResponse.ErrorText)
+		}
+		if controlResponse.Status != 0 {
+			return verifyError(controlResponse.Status, controlResponse.ErrorText)
+		}
+		return nil
+	})
+	return controlResponse, err
+}
+
+// verifyStatusWrongState is the status of a control command that can't be
+// carried out at this point in the verification.
+const verifyStatusWrongState = 19
+
+var (
+	// ErrVerifyCancelTooEarly is returned when cancelling a verification in
+	// the first 30 seconds after it was requested. Retry once they have
+	// passed.
+	ErrVerifyCancelTooEarly = errors.New("Verify can not be cancelled within 30 seconds of the request")
+
+	// ErrVerifyCancelTooLate is returned when cancelling a verification that
+	// has already moved on to its second delivery attempt, about five
+	// minutes after it was requested. Retrying will not help.
+	ErrVerifyCancelTooLate = errors.New("Verify can no longer be cancelled")
+)
+
+func verifyError(status int, errorText string) error {
+	return fmt.Errorf("Verify failed (status %d): %s", status, errorText)
+}
+
+// post sends values to the Verify endpoint path, decodes the response into
+// out and returns the error check reports for it.
+func (c *Verify) post(path string, values url.Values, out interface{}, check func() error) (err error) {
+	values.Set("api_key", c.client.apiKey)
+	values.Set("api_secret", c.client.apiSecret)
+
+	r, _ := http.NewRequest("POST", c.client.apiURL+path, strings.NewReader(values.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	defer c.client.wrapError(r, &err)
+	resp, err := c.client.do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return err
+	}
+	return check()
+}
+
+// VerifySession is a verification in progress. It remembers the request ID so
+// callers don't have to. A session may be handed between goroutines but must
+// not be used by more than one at a time. To continue a session in another
+// process, persist its RequestID and StartedAt and pass them to
+// RestoreVerifySession.
+type VerifySession struct {
+	verify    *Verify
+	requestID string
+	startedAt time.Time
+}
+
+// Start starts a verification of number, returning a session to check the
+// code the user enters.
+func (c *Verify) Start(number, brand string) (*VerifySession, error) {
+	verifyResponse, err := c.Request(&VerifyRequest{Number: number, Brand: brand})
+	if err != nil {
+		return nil, err
+	}
+	return c.RestoreVerifySession(verifyResponse.RequestID, c.client.clock.Now()), nil
+}
+
+// RestoreVerifySession returns the session of a verification started earlier,
+// possibly by another process, from its request ID and start time.
+func (c *Verify) RestoreVerifySession(requestID string, startedAt time.Time) *VerifySession {
+	return &VerifySession{verify: c, requestID: requestID, startedAt: startedAt}
+}
+
+// RequestID returns the Nexmo request ID of the verification.
+func (s *VerifySession) RequestID() string {
+	return s.requestID
+}
+
+// StartedAt returns the time the verification was requested.
+func (s *VerifySession) StartedAt() time.Time {
+	return s.startedAt
+}
+
+// Check checks the code the user entered.
+func (s *VerifySession) Check(code string) error {
+	_, err := s.verify.Check(s.requestID, code)
+	return err
+}
+
+// Cancel cancels the verification, e.g. when the user abandons it, stopping
+// further delivery attempts. Nexmo only allows this between 30 seconds and
+// about five minutes after the request; outside that window Cancel returns
+// an error wrapping ErrVerifyCancelTooEarly or ErrVerifyCancelTooLate.
+func (s *VerifySession) Cancel() error {
+	_, err := s.verify.Control(s.requestID, VerifyCancel)
+	return err
+}
+
+// Resend moves the verification on to its next delivery attempt.
+func (s *VerifySession) Resend() error {
+	_, err := s.verify.Control(s.requestID, VerifyTriggerNextEvent)
+	return err
+}
@@ -0,0 +1,128 @@
+package nexmo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestVerifyCheckResponseCost(t *testing.T) {
+	body := `{"request_id":"abcdef0123456789abcdef0123456789","event_id":"0A00000012345678",
+		"status":"0","price":"0.10000000","currency":"EUR"}`
+
+	var checkResponse *VerifyCheckResponse
+	if err := json.Unmarshal([]byte(body), &checkResponse); err != nil {
+		t.Fatal("Failed to decode check response with error:", err)
+	}
+
+	cost := checkResponse.Cost()
+	if cost.Amount != 0.1 || cost.Currency != "EUR" {
+		t.Errorf("Cost() = %v, want 0.1 EUR", cost)
+	}
+}
+
+var verifyCancelTests = []struct {
+	body    string
+	wantErr error
+}{
+	{`{"status": "0", "command": "cancel"}`, nil},
+	{`{"status": "19", "error_text": "Verification request [abcdef0123456789abcdef0123456789] can't be cancelled within the first 30 seconds."}`,
+		ErrVerifyCancelTooEarly},
+	{`{"status": "19", "error_text": "Verification request [abcdef0123456789abcdef0123456789] can't be cancelled now. Too many attempts to re-deliver have already been made."}`,
+		ErrVerifyCancelTooLate},
+}
+
+func TestVerifySessionCancel(t *testing.T) {
+	var body string
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	session := nexmo.Verify.RestoreVerifySession("abcdef0123456789abcdef0123456789", time.Now())
+
+	for _, test := range verifyCancelTests {
+		body = test.body
+		err := session.Cancel()
+		if test.wantErr == nil && err != nil {
+			t.Errorf("Cancel() with response %s failed with error: %v", test.body, err)
+		}
+		if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+			t.Errorf("Cancel() with response %s = %v, want %v", test.body, err, test.wantErr)
+		}
+	}
+}
+
+func TestRestoreVerifySession(t *testing.T) {
+	var checked url.Values
+	nexmo, clk := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/verify/json":
+			w.Write([]byte(`{"request_id": "abcdef0123456789abcdef0123456789", "status": "0"}`))
+		case "/verify/check/json":
+			checked = r.PostForm
+			w.Write([]byte(`{"request_id": "abcdef0123456789abcdef0123456789", "status": "0", "price": "0.10000000", "currency": "EUR"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	session, err := nexmo.Verify.Start("447700900000", "gonexmo")
+	if err != nil {
+		t.Fatal("Start failed with error:", err)
+	}
+	if !session.StartedAt().Equal(clk.Now()) {
+		t.Errorf("StartedAt() = %v, want %v", session.StartedAt(), clk.Now())
+	}
+
+	// Persist the session, then pick it up again, e.g. in another process.
+	requestID, startedAt := session.RequestID(), session.StartedAt()
+	restored := nexmo.Verify.RestoreVerifySession(requestID, startedAt)
+	if err := restored.Check("1234"); err != nil {
+		t.Fatal("Check failed with error:", err)
+	}
+	if checked.Get("request_id") != requestID || checked.Get("code") != "1234" {
+		t.Errorf("Check sent %v, want request_id %s and code 1234", checked, requestID)
+	}
+	if !restored.StartedAt().Equal(startedAt) {
+		t.Errorf("Restored StartedAt() = %v, want %v", restored.StartedAt(), startedAt)
+	}
+}
+
+var verifyRequestValidationTests = []struct {
+	brand    string
+	senderID string
+	wantErr  bool
+}{
+	{"gonexmo", "", false},
+	{"Eighteen chars brd", "", false},
+	{"Nineteen chars brnd", "", true},
+	{"Café Crème Brûlée", "", false},
+	{"gonexmo", "gonexmo", false},
+	{"gonexmo", "Eleven Char", false},
+	{"gonexmo", "Twelve Chars", true},
+	{"gonexmo", "go-nexmo", true},
+	{"gonexmo", "447700900000", false},
+	{"gonexmo", "4477009000001234", true},
+}
+
+func TestVerifyRequestValidation(t *testing.T) {
+	var requests int
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"request_id": "abcdef0123456789abcdef0123456789", "status": "0"}`))
+	}))
+
+	for _, test := range verifyRequestValidationTests {
+		requests = 0
+		_, err := nexmo.Verify.Request(&VerifyRequest{Number: "447700900000", Brand: test.brand, SenderID: test.senderID})
+		if (err != nil) != test.wantErr {
+			t.Errorf("Request with brand %q and sender ID %q = %v, want error: %v", test.brand, test.senderID, err, test.wantErr)
+		}
+		if test.wantErr && requests != 0 {
+			t.Errorf("Invalid request with brand %q and sender ID %q was sent", test.brand, test.senderID)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package nexmo
+
+import "strings"
+
+// Languages Verify can send its messages and calls in, for VerifyRequest.Lg.
+const (
+	VerifyLangArabic             = "ar-xa"
+	VerifyLangCzech              = "cs-cz"
+	VerifyLangWelsh              = "cy-gb"
+	VerifyLangDanish             = "da-dk"
+	VerifyLangGerman             = "de-de"
+	VerifyLangGreek              = "el-gr"
+	VerifyLangEnglishAustralia   = "en-au"
+	VerifyLangEnglishUK          = "en-gb"
+	VerifyLangEnglishIndia       = "en-in"
+	VerifyLangEnglishUS          = "en-us"
+	VerifyLangSpanishSpain       = "es-es"
+	VerifyLangSpanishMexico      = "es-mx"
+	VerifyLangSpanishUS          = "es-us"
+	VerifyLangFinnish            = "fi-fi"
+	VerifyLangFilipino           = "fil-ph"
+	VerifyLangFrenchCanada       = "fr-ca"
+	VerifyLangFrenchFrance       = "fr-fr"
+	VerifyLangHindi              = "hi-in"
+	VerifyLangHungarian          = "hu-hu"
+	VerifyLangIndonesian         = "id-id"
+	VerifyLangIcelandic          = "is-is"
+	VerifyLangItalian            = "it-it"
+	VerifyLangJapanese           = "ja-jp"
+	VerifyLangKorean             = "ko-kr"
+	VerifyLangNorwegian          = "nb-no"
+	VerifyLangDutch              = "nl-nl"
+	VerifyLangPolish             = "pl-pl"
+	VerifyLangPortugueseBrazil   = "pt-br"
+	VerifyLangPortuguesePortugal = "pt-pt"
+	VerifyLangRomanian           = "ro-ro"
+	VerifyLangRussian            = "ru-ru"
+	VerifyLangSwedish            = "sv-se"
+	VerifyLangThai               = "th-th"
+	VerifyLangTurkish            = "tr-tr"
+	VerifyLangVietnamese         = "vi-vn"
+	VerifyLangCantonese          = "yue-cn"
+	VerifyLangChineseSimplified  = "zh-cn"
+	VerifyLangChineseTraditional = "zh-tw"
+)
+
+// DefaultVerifyLang is the language Verify uses when none is given, and the
+// one ClosestSupportedLang falls back to.
+const DefaultVerifyLang = VerifyLangEnglishUS
+
+// verifyLangs holds every supported language, in the order
+// ClosestSupportedLang prefers them among the variants of a language.
+var verifyLangs = []string{
+	VerifyLangArabic, VerifyLangCzech, VerifyLangWelsh, VerifyLangDanish,
+	VerifyLangGerman, VerifyLangGreek, VerifyLangEnglishUS, VerifyLangEnglishUK,
+	VerifyLangEnglishAustralia, VerifyLangEnglishIndia, VerifyLangSpanishSpain,
+	VerifyLangSpanishMexico, VerifyLangSpanishUS, VerifyLangFinnish,
+	VerifyLangFilipino, VerifyLangFrenchFrance, VerifyLangFrenchCanada,
+	VerifyLangHindi, VerifyLangHungarian, VerifyLangIndonesian,
+	VerifyLangIcelandic, VerifyLangItalian, VerifyLangJapanese, VerifyLangKorean,
+	VerifyLangNorwegian, VerifyLangDutch, VerifyLangPolish,
+	VerifyLangPortugueseBrazil, VerifyLangPortuguesePortugal, VerifyLangRomanian,
+	VerifyLangRussian, VerifyLangSwedish, VerifyLangThai, VerifyLangTurkish,
+	VerifyLangVietnamese, VerifyLangCantonese, VerifyLangChineseSimplified,
+	VerifyLangChineseTraditional,
+}
+
+// langAliases maps language subtags to the one Verify uses for them.
+var langAliases = map[string]string{
+	"no": "nb",
+	"nn": "nb",
+	"tl": "fil",
+}
+
+// IsSupportedVerifyLang reports whether Verify accepts lang, e.g. "en-gb", as
+// the language of a verification.
+func IsSupportedVerifyLang(lang string) bool {
+	for _, supported := range verifyLangs {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ClosestSupportedLang returns the Verify language closest to locale, which
+// may be a BCP 47 tag such as "pt-BR" or a POSIX locale such as
+// "fr_CA.UTF-8". A locale whose region isn't supported gets another region
+// of the same language, e.g. "en-NZ" gets "en-us", and one whose language
+// isn't supported gets DefaultVerifyLang.
+func ClosestSupportedLang(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return DefaultVerifyLang
+	}
+
+	lang := parts[0]
+	if alias, ok := langAliases[lang]; ok {
+		lang = alias
+	}
+	for _, part := range parts[1:] {
+		switch {
+		case lang == "zh" && (part == "hant" || part == "hk" || part == "mo"):
+			return VerifyLangChineseTraditional
+		case len(part) == 2 && IsSupportedVerifyLang(lang+"-"+part):
+			return lang + "-" + part
+		}
+	}
+	for _, supported := range verifyLangs {
+		if strings.HasPrefix(supported, lang+"-") {
+			return supported
+		}
+	}
+	return DefaultVerifyLang
+}
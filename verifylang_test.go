@@ -0,0 +1,65 @@
+package nexmo
+
+import (
+	"net/http"
+	"testing"
+)
+
+var closestLangTests = []struct {
+	locale string
+	want   string
+}{
+	{"en-us", "en-us"},
+	{"en-GB", "en-gb"},
+	{"en_IN.UTF-8", "en-in"},
+	{"en-NZ", "en-us"},
+	{"en", "en-us"},
+	{"pt-BR", "pt-br"},
+	{"pt", "pt-br"},
+	{"fr_CA", "fr-ca"},
+	{"fr-BE", "fr-fr"},
+	{"de-AT", "de-de"},
+	{"no", "nb-no"},
+	{"zh-Hans-CN", "zh-cn"},
+	{"zh-Hant", "zh-tw"},
+	{"zh-HK", "zh-tw"},
+	{"sr-RS", "en-us"},
+	{"", "en-us"},
+}
+
+func TestClosestSupportedLang(t *testing.T) {
+	for _, test := range closestLangTests {
+		if got := ClosestSupportedLang(test.locale); got != test.want {
+			t.Errorf("ClosestSupportedLang(%q) = %q, want %q", test.locale, got, test.want)
+		}
+		if !IsSupportedVerifyLang(test.want) {
+			t.Errorf("%q is not a supported language", test.want)
+		}
+	}
+}
+
+func TestVerifyRequestLang(t *testing.T) {
+	var requests int
+	var lg string
+	nexmo, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		r.ParseForm()
+		lg = r.PostForm.Get("lg")
+		w.Write([]byte(`{"request_id": "abcdef0123456789abcdef0123456789", "status": "0"}`))
+	}))
+
+	req := &VerifyRequest{Number: "447700900000", Brand: "gonexmo", Lg: "en-nz"}
+	if _, err := nexmo.Verify.Request(req); err == nil || requests != 0 {
+		t.Errorf("Request with an unsupported language = %v after %d requests, want a local error", err, requests)
+	}
+
+	req.Lg = VerifyLangEnglishUK
+	if _, err := nexmo.Verify.Request(req); err != nil {
+		t.Error("Request with a supported language failed with error:", err)
+	}
+
+	req.Lg = "en-GB"
+	if _, err := nexmo.Verify.Request(req); err != nil || lg != VerifyLangEnglishUK {
+		t.Errorf("Request with language en-GB = %v, sent lg %q, want %q", err, lg, VerifyLangEnglishUK)
+	}
+}
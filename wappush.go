@@ -0,0 +1,88 @@
+package nexmo
+
+import (
+	"errors"
+	"strings"
+)
+
+// WAPPushMode is the kind of WAP Push message SetWAPPush makes.
+type WAPPushMode int
+
+const (
+	// WAPPushSI is a Service Indication: a notification showing a title
+	// and a link the recipient can choose to open. It is the default, and
+	// is sent with Nexmo's wappush message type.
+	WAPPushSI WAPPushMode = iota
+
+	// WAPPushSL is a Service Loading message, which the handset opens
+	// without asking. Nexmo's wappush type only sends Service Indications,
+	// so it is sent as a binary message carrying a WBXML encoded SL
+	// document. Many handsets ignore SL from untrusted senders.
+	WAPPushSL
+)
+
+// slHrefPrefixes are the WBXML tokens of the SL href attribute for common
+// URL prefixes, from WAP-168, longest first.
+var slHrefPrefixes = []struct {
+	prefix string
+	token  byte
+}{
+	{"https://www.", 0x0C},
+	{"https://", 0x0B},
+	{"http://www.", 0x0A},
+	{"http://", 0x09},
+	{"", 0x08},
+}
+
+// SetWAPPush makes msg a WAP Push message of mode linking to url. title is
+// shown by WAPPushSI messages; WAPPushSL messages have none.
+func (msg *SMSMessage) SetWAPPush(mode WAPPushMode, title, url string) error {
+	if len(url) == 0 {
+		return errors.New("Invalid WAP Push URL")
+	}
+
+	switch mode {
+	case WAPPushSI:
+		if len(title) == 0 {
+			return errors.New("Invalid WAP Push title")
+		}
+		msg.Type = WAPPush
+		msg.Title = title
+		msg.URL = url
+	case WAPPushSL:
+		msg.SetBinary(BinaryWAPPush, serviceLoading(url))
+		msg.Title = ""
+		msg.URL = ""
+	default:
+		return errors.New("Invalid WAP Push mode")
+	}
+	return nil
+}
+
+// serviceLoading returns the WSP push PDU of an SL document for url, with
+// the execute-high action so it opens at once.
+func serviceLoading(url string) []byte {
+	pdu := []byte{
+		0x01,        // Transaction ID.
+		0x06,        // PDU type: push.
+		0x01,        // Headers length.
+		0xB0,        // Content type: application/vnd.wap.slc.
+		0x02,        // WBXML version 1.2.
+		0x06,        // Public ID: -//WAPFORUM//DTD SL 1.0//EN.
+		0x6A,        // Charset: UTF-8.
+		0x00,        // String table length.
+		0x05 | 0x80, // sl, with attributes.
+	}
+	for _, p := range slHrefPrefixes {
+		if strings.HasPrefix(url, p.prefix) {
+			pdu = append(pdu, p.token, 0x03) // href with the prefix, then an inline string.
+			pdu = append(pdu, url[len(p.prefix):]...)
+			pdu = append(pdu, 0x00)
+			break
+		}
+	}
+	return append(pdu,
+		0x06, // action="execute-high"
+		0x01, // End of attributes.
+	)
+}
@@ -0,0 +1,92 @@
+package nexmo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Warning codes.
+const (
+	// WarningMessageCountMismatch means a response's message-count didn't
+	// match the number of message reports in it.
+	WarningMessageCountMismatch = "message-count-mismatch"
+
+	// WarningUnknownField means a response had a field the package doesn't
+	// know, which may be a sign of API changes.
+	WarningUnknownField = "unknown-field"
+)
+
+// Warning is a soft issue found in a response from Nexmo, such as a sign of
+// API drift, that doesn't fail the call.
+type Warning struct {
+	Code    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Code + ": " + w.Message
+}
+
+// Fields known in JSON send responses.
+var (
+	knownResponseFields = map[string]bool{"message-count": true, "messages": true}
+	knownReportFields   = map[string]bool{
+		"status": true, "message-id": true, "to": true, "client-ref": true,
+		"remaining-balance": true, "message-price": true, "network": true,
+		"error-text": true, "account-ref": true,
+	}
+)
+
+// responseWarnings returns the warnings for resp, decoded from body. Unknown
+// fields are only looked for in JSON bodies.
+func responseWarnings(body []byte, resp *MessageResponse, isJSON bool) []Warning {
+	var warnings []Warning
+	if resp.MessageCount != len(resp.Messages) {
+		warnings = append(warnings, Warning{WarningMessageCountMismatch,
+			fmt.Sprintf("message-count %d does not match %d message reports", resp.MessageCount, len(resp.Messages))})
+	}
+	if !isJSON {
+		return warnings
+	}
+
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return warnings
+	}
+	unknown := unknownFields(raw, knownResponseFields, "")
+	var reports []map[string]json.RawMessage
+	if json.Unmarshal(raw["messages"], &reports) == nil {
+		for i, report := range reports {
+			unknown = append(unknown, unknownFields(report, knownReportFields, fmt.Sprintf("messages[%d].", i))...)
+		}
+	}
+	for _, name := range unknown {
+		warnings = append(warnings, Warning{WarningUnknownField, "unknown response field " + name})
+	}
+	return warnings
+}
+
+// unknownFields returns the sorted names of the fields of raw not in known,
+// with prefix.
+func unknownFields(raw map[string]json.RawMessage, known map[string]bool, prefix string) []string {
+	var names []string
+	for name := range raw {
+		if !known[name] {
+			names = append(names, prefix+name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// warn passes warnings to OnWarning, if set, and the verbose log.
+func (c *Client) warn(ctx context.Context, warnings []Warning) {
+	for _, w := range warnings {
+		c.logf(ctx, "Warning: %s", w)
+		if c.OnWarning != nil {
+			c.OnWarning(w)
+		}
+	}
+}